@@ -0,0 +1,127 @@
+package valuator
+
+import (
+	"encoding/json"
+	"log"
+)
+
+// Zone is a type definition for the bankruptcy risk zones of the Altman Z
+type Zone string
+
+// ZoneSafe is a low risk of distress
+const ZoneSafe Zone = "safe"
+
+// ZoneGrey is a moderate risk of distress
+const ZoneGrey Zone = "grey"
+
+// ZoneDistress is a high risk of distress
+const ZoneDistress Zone = "distress"
+
+// AltmanZ provides an interface to the Altman Z-score bankruptcy risk metric
+type AltmanZ interface {
+	// Z is the original score for manufacturers
+	Z() float64
+	Zone() Zone
+	// ZDoublePrime is the score for non-manufacturers
+	ZDoublePrime() float64
+	ZDoublePrimeZone() Zone
+	// MarketValue is true if the market value of equity was used for Z and
+	// false if book equity was used in its place
+	MarketValue() bool
+	String() string
+}
+
+type altmanZ struct {
+	Score    float64 `json:"Z"`
+	ZZone    Zone    `json:"Zone"`
+	ScoreDP  float64 `json:"Z''"`
+	ZoneDP   Zone    `json:"Z'' Zone"`
+	MarketEq bool    `json:"Market Value of Equity"`
+}
+
+func (a altmanZ) String() string {
+	data, err := json.MarshalIndent(a, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling Altman Z data: ", err)
+	}
+	return string(data)
+}
+
+func zone(z float64, safe float64, distress float64) Zone {
+	switch {
+	case z > safe:
+		return ZoneSafe
+	case z < distress:
+		return ZoneDistress
+	default:
+		return ZoneGrey
+	}
+}
+
+// newAltmanZ computes the Z scores of a filing
+//
+//	A = Working capital/Total assets
+//	B = Retained earnings/Total assets
+//	C = Operating income/Total assets
+//	D = Market value of equity/Total liabilities
+//	E = Revenue/Total assets
+//	Z = 1.2A + 1.4B + 3.3C + 0.6D + 1.0E (safe > 2.99, distress < 1.81)
+//	Z'' = 6.56A + 3.26B + 6.72C + 1.05D'' (safe > 2.6, distress < 1.1)
+//
+// The Z double prime uses book equity for D. When marketCap is not positive
+// book equity is used for the Z as well. Returns nil when the balance sheet
+// totals are not known.
+func newAltmanZ(m Measures, marketCap float64) *altmanZ {
+	f := m.Filing()
+	as, err := totalAssets(f)
+	if err != nil || as == 0 {
+		return nil
+	}
+	liab, err := totalLiabilities(f)
+	if err != nil || liab == 0 {
+		return nil
+	}
+	eq, err := f.TotalEquity()
+	if err != nil {
+		return nil
+	}
+	re, _ := f.RetainedEarnings()
+	oi, _ := f.OperatingIncome()
+	rev, _ := f.Revenue()
+
+	a := m.WorkingCapital() / as
+	b := re / as
+	c := oi / as
+	e := rev / as
+
+	ret := &altmanZ{MarketEq: marketCap > 0}
+	mve := eq
+	if ret.MarketEq {
+		mve = marketCap
+	}
+	ret.Score = round(1.2*a + 1.4*b + 3.3*c + 0.6*(mve/liab) + 1.0*e)
+	ret.ZZone = zone(ret.Score, 2.99, 1.81)
+	ret.ScoreDP = round(6.56*a + 3.26*b + 6.72*c + 1.05*(eq/liab))
+	ret.ZoneDP = zone(ret.ScoreDP, 2.6, 1.1)
+	return ret
+}
+
+func (a *altmanZ) Z() float64 {
+	return a.Score
+}
+
+func (a *altmanZ) Zone() Zone {
+	return a.ZZone
+}
+
+func (a *altmanZ) ZDoublePrime() float64 {
+	return a.ScoreDP
+}
+
+func (a *altmanZ) ZDoublePrimeZone() Zone {
+	return a.ZoneDP
+}
+
+func (a *altmanZ) MarketValue() bool {
+	return a.MarketEq
+}
@@ -0,0 +1,323 @@
+package valuator
+
+import (
+	"log"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Restatement is a value of a filing that was changed by a later filing for
+// the same fiscal year
+type Restatement struct {
+	Field    string    `json:"Field"`
+	Original float64   `json:"Original"`
+	Restated float64   `json:"Restated"`
+	Change   float64   `json:"Change (%)"`
+	On       Timestamp `json:"Restated On"`
+}
+
+// filingFields are the values compared between the versions of a filing
+var filingFields = []struct {
+	name string
+	fn   func(ReportedFiling) (float64, error)
+}{
+	{"Share Count", ReportedFiling.ShareCount},
+	{"Revenue", ReportedFiling.Revenue},
+	{"Cost Of Revenue", ReportedFiling.CostOfRevenue},
+	{"Gross Margin", ReportedFiling.GrossMargin},
+	{"Operating Income", ReportedFiling.OperatingIncome},
+	{"Operating Expense", ReportedFiling.OperatingExpense},
+	{"Net Income", ReportedFiling.NetIncome},
+	{"Total Equity", ReportedFiling.TotalEquity},
+	{"Short Term Debt", ReportedFiling.ShortTermDebt},
+	{"Long Term Debt", ReportedFiling.LongTermDebt},
+	{"Current Liabilities", ReportedFiling.CurrentLiabilities},
+	{"Current Assets", ReportedFiling.CurrentAssets},
+	{"Deferred Revenue", ReportedFiling.DeferredRevenue},
+	{"Retained Earnings", ReportedFiling.RetainedEarnings},
+	{"Operating Cash Flow", ReportedFiling.OperatingCashFlow},
+	{"Capital Expenditure", ReportedFiling.CapitalExpenditure},
+	{"Dividend", ReportedFiling.Dividend},
+	{"Dividend Per Share", ReportedFiling.DividendPerShare},
+	{"Weighted Average Shares", ReportedFiling.WAShares},
+	{"Cash", ReportedFiling.Cash},
+	{"Securities", ReportedFiling.Securities},
+	{"Goodwill", ReportedFiling.Goodwill},
+	{"Intangibles", ReportedFiling.Intangibles},
+	{"Total Assets", ReportedFiling.Assets},
+	{"Total Liabilities", ReportedFiling.Liabilities},
+}
+
+// amendedFiling is the filing of a fiscal year along with its amendments.
+// Amendments often only carry the values that changed, so every value is
+// taken from the latest version that has it. Prior year figures restated in
+// the comparatives of a later annual report are not collected, so only the
+// amendments, ex: 10-K/A, restate a fiscal year
+type amendedFiling struct {
+	versions []Filing
+}
+
+// reconcileFilings merges the filings of each fiscal year, the original and
+// any amendments, into one filing with the latest values
+func reconcileFilings(fs []Filing) []Filing {
+	byYear := make(map[int][]Filing)
+	var years []int
+	for _, f := range fs {
+		if _, ok := byYear[f.FiscalYear()]; !ok {
+			years = append(years, f.FiscalYear())
+		}
+		byYear[f.FiscalYear()] = append(byYear[f.FiscalYear()], f)
+	}
+	sort.Ints(years)
+
+	var ret []Filing
+	for _, y := range years {
+		versions := byYear[y]
+		if len(versions) == 1 {
+			ret = append(ret, versions[0])
+			continue
+		}
+		sort.SliceStable(versions, func(i, j int) bool {
+			return versions[i].FiledOn().Before(versions[j].FiledOn())
+		})
+		ret = append(ret, &amendedFiling{versions: versions})
+	}
+	return ret
+}
+
+// newAmendment puts an amendment in the fiscal year it reports amending. The
+// filing date of an amendment says little about its period, so amendments
+// that do not report their period, or that amend a fiscal year with no
+// filing before them, are dropped
+func newAmendment(fs []Filing, f ReportedFiling) Filing {
+	if ff, ok := f.(Filing); ok {
+		return inUnits(ff, f)
+	}
+	filed := Timestamp(f.FiledOn()).String()
+	end, year, err := reportedPeriod(f)
+	if err != nil {
+		log.Println("Dropping the amendment of "+f.Ticker()+" filed on "+filed+": ", err)
+		return nil
+	}
+	for _, o := range fs {
+		if o.FiscalYear() != year || o.FiledOn().After(f.FiledOn()) {
+			continue
+		}
+		return inUnits(&fiscalFiling{
+			ReportedFiling: f,
+			year:           year,
+			end:            end,
+			currency:       o.Currency(),
+		}, f)
+	}
+	log.Println("Dropping the amendment of " + f.Ticker() + " filed on " + filed +
+		": No filing of fiscal year " + strconv.Itoa(year))
+	return nil
+}
+
+// filingAsOf gets the version of a filing known on the date or nil if the
+// filing was not made by then
+func filingAsOf(f Filing, date time.Time) Filing {
+	a, ok := f.(*amendedFiling)
+	if !ok {
+		if f.FiledOn().After(date) {
+			return nil
+		}
+		return f
+	}
+	var known []Filing
+	for _, v := range a.versions {
+		if !v.FiledOn().After(date) {
+			known = append(known, v)
+		}
+	}
+	switch len(known) {
+	case 0:
+		return nil
+	case 1:
+		return known[0]
+	}
+	return &amendedFiling{versions: known}
+}
+
+// originalFiling is the filing as it was first filed
+func originalFiling(f Filing) Filing {
+	if a, ok := f.(*amendedFiling); ok {
+		return a.versions[0]
+	}
+	return f
+}
+
+// restatements lists the values of the original filing that were changed by
+// the amendments along with the % change
+func restatements(f Filing) []Restatement {
+	a, ok := f.(*amendedFiling)
+	if !ok {
+		return nil
+	}
+	orig := a.versions[0]
+	var ret []Restatement
+	for _, field := range filingFields {
+		o, oerr := field.fn(orig)
+		r, rerr := field.fn(a)
+		if rerr != nil || (oerr == nil && o == r) {
+			continue
+		}
+		rs := Restatement{
+			Field:    field.name,
+			Original: o,
+			Restated: r,
+			On:       Timestamp(a.restatedOn(field.fn)),
+		}
+		if oerr == nil && o != 0 {
+			rs.Change = round((r - o) / o * 100)
+		}
+		ret = append(ret, rs)
+	}
+	return ret
+}
+
+// latest gets the value from the latest version that has it
+func (a *amendedFiling) latest(fn func(ReportedFiling) (float64, error)) (float64, error) {
+	var err error
+	for i := len(a.versions) - 1; i >= 0; i-- {
+		var val float64
+		if val, err = fn(a.versions[i]); err == nil {
+			return val, nil
+		}
+	}
+	return 0, err
+}
+
+// restatedOn is the filing date of the version the value is taken from
+func (a *amendedFiling) restatedOn(fn func(ReportedFiling) (float64, error)) time.Time {
+	for i := len(a.versions) - 1; i >= 0; i-- {
+		if _, err := fn(a.versions[i]); err == nil {
+			return a.versions[i].FiledOn()
+		}
+	}
+	return a.versions[0].FiledOn()
+}
+
+func (a *amendedFiling) Ticker() string {
+	return a.versions[0].Ticker()
+}
+
+// FiledOn is the date of the original filing, when the fiscal year was first
+// known. Use filingAsOf for the values known on a date
+func (a *amendedFiling) FiledOn() time.Time {
+	return a.versions[0].FiledOn()
+}
+
+func (a *amendedFiling) FiscalYear() int {
+	return a.versions[0].FiscalYear()
+}
+
+func (a *amendedFiling) PeriodEnd() time.Time {
+	return a.versions[0].PeriodEnd()
+}
+
+func (a *amendedFiling) Currency() string {
+	return a.versions[0].Currency()
+}
+
+func (a *amendedFiling) ShareCount() (float64, error) {
+	return a.latest(ReportedFiling.ShareCount)
+}
+
+func (a *amendedFiling) Revenue() (float64, error) {
+	return a.latest(ReportedFiling.Revenue)
+}
+
+func (a *amendedFiling) CostOfRevenue() (float64, error) {
+	return a.latest(ReportedFiling.CostOfRevenue)
+}
+
+func (a *amendedFiling) GrossMargin() (float64, error) {
+	return a.latest(ReportedFiling.GrossMargin)
+}
+
+func (a *amendedFiling) OperatingIncome() (float64, error) {
+	return a.latest(ReportedFiling.OperatingIncome)
+}
+
+func (a *amendedFiling) OperatingExpense() (float64, error) {
+	return a.latest(ReportedFiling.OperatingExpense)
+}
+
+func (a *amendedFiling) NetIncome() (float64, error) {
+	return a.latest(ReportedFiling.NetIncome)
+}
+
+func (a *amendedFiling) TotalEquity() (float64, error) {
+	return a.latest(ReportedFiling.TotalEquity)
+}
+
+func (a *amendedFiling) ShortTermDebt() (float64, error) {
+	return a.latest(ReportedFiling.ShortTermDebt)
+}
+
+func (a *amendedFiling) LongTermDebt() (float64, error) {
+	return a.latest(ReportedFiling.LongTermDebt)
+}
+
+func (a *amendedFiling) CurrentLiabilities() (float64, error) {
+	return a.latest(ReportedFiling.CurrentLiabilities)
+}
+
+func (a *amendedFiling) CurrentAssets() (float64, error) {
+	return a.latest(ReportedFiling.CurrentAssets)
+}
+
+func (a *amendedFiling) DeferredRevenue() (float64, error) {
+	return a.latest(ReportedFiling.DeferredRevenue)
+}
+
+func (a *amendedFiling) RetainedEarnings() (float64, error) {
+	return a.latest(ReportedFiling.RetainedEarnings)
+}
+
+func (a *amendedFiling) OperatingCashFlow() (float64, error) {
+	return a.latest(ReportedFiling.OperatingCashFlow)
+}
+
+func (a *amendedFiling) CapitalExpenditure() (float64, error) {
+	return a.latest(ReportedFiling.CapitalExpenditure)
+}
+
+func (a *amendedFiling) Dividend() (float64, error) {
+	return a.latest(ReportedFiling.Dividend)
+}
+
+func (a *amendedFiling) DividendPerShare() (float64, error) {
+	return a.latest(ReportedFiling.DividendPerShare)
+}
+
+func (a *amendedFiling) WAShares() (float64, error) {
+	return a.latest(ReportedFiling.WAShares)
+}
+
+func (a *amendedFiling) Cash() (float64, error) {
+	return a.latest(ReportedFiling.Cash)
+}
+
+func (a *amendedFiling) Securities() (float64, error) {
+	return a.latest(ReportedFiling.Securities)
+}
+
+func (a *amendedFiling) Goodwill() (float64, error) {
+	return a.latest(ReportedFiling.Goodwill)
+}
+
+func (a *amendedFiling) Intangibles() (float64, error) {
+	return a.latest(ReportedFiling.Intangibles)
+}
+
+func (a *amendedFiling) Assets() (float64, error) {
+	return a.latest(ReportedFiling.Assets)
+}
+
+func (a *amendedFiling) Liabilities() (float64, error) {
+	return a.latest(ReportedFiling.Liabilities)
+}
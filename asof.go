@@ -0,0 +1,107 @@
+package valuator
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"time"
+)
+
+// asOfView is a valuator restricted to what was publicly known on a date.
+// The measures, averages and price metrics are computed again from the
+// filings of the date so nothing filed later can leak into them
+type asOfView struct {
+	*valuator
+	date time.Time
+}
+
+func (a *asOfView) String() string {
+	data, err := json.MarshalIndent(struct {
+		Date       Timestamp             `json:"As Of"`
+		Valuations map[string]*valuation `json:"Company"`
+	}{Timestamp(a.date), a.Valuations}, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling point in time data: ", err)
+	}
+	return string(data)
+}
+
+// Collect is not allowed as the collected data is not as of the date
+func (a *asOfView) Collect(ticker string) error {
+	return errors.New("Cannot collect " + ticker + " on a view as of " + getDateString(a.date))
+}
+
+// Write is not allowed so the database is not replaced with partial data
+func (a *asOfView) Write() error {
+	return errors.New("Cannot write a view as of " + getDateString(a.date))
+}
+
+// AsOf creates a view of the collected tickers with the filings made on or
+// before the date, as amended by then. The prices are the close on the date
+// from the source, if any. Tickers with less than two filings by then are
+// left out as they have no averages
+func (v *valuator) AsOf(date time.Time, prices PriceSource) (Valuator, error) {
+	pit, err := v.newView()
+	if err != nil {
+		return nil, err
+	}
+	view := &asOfView{valuator: pit, date: date}
+
+	for ticker, vals := range v.Valuations {
+		// Tickers with no close on the date have no price based metrics
+		var price float64
+		if prices != nil {
+			if closes, err := prices.DailyCloses(ticker); err == nil {
+				price, _ = closeOn(closes, date)
+				price = v.sharePrice(ticker, price)
+			}
+		}
+		val, err := valuationAsOf(vals, date, price)
+		if err != nil {
+			log.Println("Leaving out " + ticker + " as of " + getDateString(date) + ": " + err.Error())
+			continue
+		}
+		view.Valuations[ticker] = val
+	}
+	return view, nil
+}
+
+// valuationAsOf computes a valuation again from the filings made on or before
+// the date, as amended by then, at the price
+func valuationAsOf(vals *valuation, date time.Time, price float64) (*valuation, error) {
+	mea, err := measuresAsOf(vals, date)
+	if err != nil {
+		return nil, err
+	}
+	avg, err := newAverages(mea)
+	if err != nil {
+		return nil, err
+	}
+	return &valuation{
+		Ticker:    vals.Ticker,
+		Date:      Timestamp(date),
+		FiledData: mea,
+		Avgs:      avg,
+		Pbm:       newPriceBasedMetricsAt(mea[len(mea)-1], avg, price),
+	}, nil
+}
+
+// measuresAsOf computes the measures again from the filings made on or
+// before the date, as amended by then
+func measuresAsOf(vals *valuation, date time.Time) ([]Measures, error) {
+	var fs []Filing
+	for _, m := range vals.FiledData {
+		// Amendments filed after the date are left out of the filing
+		if f := filingAsOf(m.Filing(), date); f != nil {
+			fs = append(fs, f)
+		}
+	}
+	if len(fs) == 0 {
+		return nil, errors.New("No filings made by " + getDateString(date))
+	}
+	mea := newMeasures(fs)
+	if err := newYoYs(mea); err != nil {
+		return nil, err
+	}
+	return mea, nil
+}
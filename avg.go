@@ -17,6 +17,16 @@ type Average interface {
 	AvgCashFlowGrowth() float64
 	AvgDividendGrowth() float64
 	AvgBookValueGrowth() float64
+	AvgOwnerEarningsGrowth() float64
+	AvgTangibleBookValueGrowth() float64
+	AvgReturnOnTangibleEquityGrowth() float64
+	AvgGoodwillToEquityGrowth() float64
+	AvgReturnOnInvestedCapitalGrowth() float64
+	AvgIncrementalReturnOnInvestedCapital() float64
+	AvgReinvestmentRate() float64
+	AvgNetShareChange() float64
+	AvgDilutionRate() float64
+	AvgPerShareGrowth(basis ShareBasis) PerShareGrowth
 }
 
 type averages struct {
@@ -29,6 +39,18 @@ type averages struct {
 	Cf        float64 `json:"Average Cash Flow Growth (%)"`
 	Div       float64 `json:"Average Dividend Growth"`
 	Bv        float64 `json:"Average Book Value Growth"`
+	Oe        float64 `json:"Average Owner Earnings Growth (%)"`
+	Tbv       float64 `json:"Average Tangible Book Value Growth"`
+	RoTE      float64 `json:"Average Return on Tangible Equity Growth (%)"`
+	GwToEq    float64 `json:"Average Goodwill to Equity Growth (%)"`
+	RoIC      float64 `json:"Average Return on Invested Capital Growth (%)"`
+	IncRoIC   float64 `json:"Average Incremental Return on Invested Capital (%)"`
+	Reinvest  float64 `json:"Average Reinvestment Rate (%)"`
+	Shares    float64 `json:"Average Net Share Change (%)"`
+	Dilution  float64 `json:"Average Dilution Rate (%)"`
+
+	// Average per share growth (%) on each basis
+	PsGrowth perShareGrowths `json:"Average Per Share Growth"`
 }
 
 func newAverages(m []Measures) (Average, error) {
@@ -56,6 +78,15 @@ func newAverages(m []Measures) (Average, error) {
 		avg.Cf = avg.Cf + val.CashFlowGrowth()
 		avg.Div = avg.Div + val.DividendGrowth()
 		avg.Bv = avg.Bv + val.BookValueGrowth()
+		avg.Oe = avg.Oe + val.OwnerEarningsGrowth()
+		avg.Tbv = avg.Tbv + val.TangibleBookValueGrowth()
+		avg.RoTE = avg.RoTE + val.ReturnOnTangibleEquityGrowth()
+		avg.GwToEq = avg.GwToEq + val.GoodwillToEquityGrowth()
+		avg.RoIC = avg.RoIC + val.ReturnOnInvestedCapitalGrowth()
+		avg.IncRoIC = avg.IncRoIC + val.IncrementalReturnOnInvestedCapital()
+		avg.Reinvest = avg.Reinvest + val.ReinvestmentRate()
+		avg.Shares = avg.Shares + val.NetShareChange()
+		avg.Dilution = avg.Dilution + val.DilutionRate()
 	}
 	avg.Revenue = avgCalc(avg.Revenue, float64(len(y)))
 	avg.Earnings = avgCalc(avg.Earnings, float64(len(y)))
@@ -66,6 +97,22 @@ func newAverages(m []Measures) (Average, error) {
 	avg.Cf = avgCalc(avg.Cf, float64(len(y)))
 	avg.Div = avgCalc(avg.Div, float64(len(y)))
 	avg.Bv = avgCalc(avg.Bv, float64(len(y)))
+	avg.Oe = avgCalc(avg.Oe, float64(len(y)))
+	avg.Tbv = avgCalc(avg.Tbv, float64(len(y)))
+	avg.RoTE = avgCalc(avg.RoTE, float64(len(y)))
+	avg.GwToEq = avgCalc(avg.GwToEq, float64(len(y)))
+	avg.RoIC = avgCalc(avg.RoIC, float64(len(y)))
+	avg.IncRoIC = avgCalc(avg.IncRoIC, float64(len(y)))
+	avg.Reinvest = avgCalc(avg.Reinvest, float64(len(y)))
+	avg.Shares = avgCalc(avg.Shares, float64(len(y)))
+	avg.Dilution = avgCalc(avg.Dilution, float64(len(y)))
+
+	avg.PsGrowth = make(perShareGrowths)
+	for _, basis := range shareBases {
+		if g := newAvgPerShareGrowth(y, basis); g != nil {
+			avg.PsGrowth[basis] = g
+		}
+	}
 
 	return avg, nil
 }
@@ -105,3 +152,46 @@ func (a *averages) AvgDividendGrowth() float64 {
 func (a *averages) AvgBookValueGrowth() float64 {
 	return a.Bv
 }
+
+func (a *averages) AvgOwnerEarningsGrowth() float64 {
+	return a.Oe
+}
+
+func (a *averages) AvgTangibleBookValueGrowth() float64 {
+	return a.Tbv
+}
+
+func (a *averages) AvgReturnOnTangibleEquityGrowth() float64 {
+	return a.RoTE
+}
+
+func (a *averages) AvgGoodwillToEquityGrowth() float64 {
+	return a.GwToEq
+}
+
+func (a *averages) AvgReturnOnInvestedCapitalGrowth() float64 {
+	return a.RoIC
+}
+
+func (a *averages) AvgIncrementalReturnOnInvestedCapital() float64 {
+	return a.IncRoIC
+}
+
+func (a *averages) AvgReinvestmentRate() float64 {
+	return a.Reinvest
+}
+
+func (a *averages) AvgNetShareChange() float64 {
+	return a.Shares
+}
+
+func (a *averages) AvgDilutionRate() float64 {
+	return a.Dilution
+}
+
+func (a *averages) AvgPerShareGrowth(basis ShareBasis) PerShareGrowth {
+	if g, ok := a.PsGrowth[basis]; ok {
+		return g
+	}
+	return nil
+}
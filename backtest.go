@@ -0,0 +1,235 @@
+package valuator
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"sort"
+	"time"
+)
+
+// BacktestOptions are the assumptions used by Backtest
+type BacktestOptions struct {
+	// Tickers to backtest. They should be collected by the valuator
+	Tickers []string
+	// Prices is the source of the price histories
+	Prices PriceSource
+	// DiscountRate, Trend and Duration are used by every valuation model
+	DiscountRate float64
+	Trend        float64
+	Duration     int
+	// HorizonDays is the number of days after the filing over which the
+	// forward return is measured
+	HorizonDays int
+	// GapBuckets are the bounds (%) of the valuation gap buckets in
+	// increasing order
+	GapBuckets []float64
+}
+
+// DefaultBacktestOptions returns the options used when none are specified
+func DefaultBacktestOptions() BacktestOptions {
+	return BacktestOptions{
+		DiscountRate: 3,
+		Trend:        100,
+		Duration:     10,
+		HorizonDays:  365,
+		GapBuckets:   []float64{-50, -25, 0, 25, 50},
+	}
+}
+
+// BacktestObservation is the valuation of a ticker by a model as of a filing
+// compared with the price that followed
+type BacktestObservation struct {
+	Ticker string         `json:"Ticker"`
+	Date   Timestamp      `json:"Date"`
+	Model  ValuationModel `json:"Model"`
+	Value  float64        `json:"Value"`
+	Price  float64        `json:"Price"`
+	// Gap is the % of the price the value is above it
+	Gap float64 `json:"Valuation Gap (%)"`
+	// ForwardReturn is the % return of the price over the horizon
+	ForwardReturn float64 `json:"Forward Return (%)"`
+	// Hit is when the sign of the gap called the sign of the forward return
+	Hit bool `json:"Hit"`
+}
+
+// GapBucket is the average forward return of the observations with a
+// valuation gap (%) in [Low, High)
+type GapBucket struct {
+	Low       float64 `json:"Low"`
+	High      float64 `json:"High"`
+	Count     int     `json:"Count"`
+	AvgReturn float64 `json:"Average Forward Return (%)"`
+}
+
+// Backtest provides an interface to the results of a valuation backtest
+type Backtest interface {
+	Observations() []BacktestObservation
+	// HitRate is the % of observations that are hits
+	HitRate() float64
+	// ModelAccuracy is the hit rate (%) of a model
+	ModelAccuracy(model ValuationModel) (float64, error)
+	Buckets() []GapBucket
+	String() string
+}
+
+type backtest struct {
+	Obs      []BacktestObservation      `json:"Observations"`
+	Hits     float64                    `json:"Hit Rate (%)"`
+	Accuracy map[ValuationModel]float64 `json:"Model Accuracy (%)"`
+	Bkts     []GapBucket                `json:"Valuation Gap Buckets"`
+}
+
+func (b backtest) String() string {
+	data, err := json.MarshalIndent(b, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling backtest data: ", err)
+	}
+	return string(data)
+}
+
+// Backtest values each ticker as of every past filing with the data known on
+// the filing date and compares the value with the price over the horizon
+func (v *valuator) Backtest(opts BacktestOptions) (Backtest, error) {
+	if opts.Prices == nil {
+		return nil, errors.New("No price source to backtest against")
+	}
+	if opts.DiscountRate <= 0 || opts.Duration <= 0 || opts.HorizonDays <= 0 {
+		return nil, errors.New("Backtest needs a positive discount rate, duration and horizon")
+	}
+	if !sort.Float64sAreSorted(opts.GapBuckets) {
+		return nil, errors.New("Valuation gap buckets should be in increasing order")
+	}
+	s := Scenario{
+		Name:         "backtest",
+		Trend:        opts.Trend,
+		DiscountRate: opts.DiscountRate,
+		Duration:     opts.Duration,
+	}
+	horizon := time.Duration(opts.HorizonDays) * 24 * time.Hour
+
+	b := &backtest{Accuracy: make(map[ValuationModel]float64)}
+	for _, ticker := range opts.Tickers {
+		vals, ok := v.Valuations[ticker]
+		if !ok {
+			return nil, errors.New("Valuator has not be told to collect data on " + ticker)
+		}
+		prices, err := opts.Prices.DailyCloses(ticker)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range vals.FiledData {
+			filed := m.Filing().FiledOn()
+			price, err := closeOn(prices, filed)
+			if err != nil || price <= 0 {
+				continue
+			}
+			fwd, err := closeOn(prices, filed.Add(horizon))
+			if err != nil {
+				continue
+			}
+			price, fwd = v.sharePrice(ticker, price), v.sharePrice(ticker, fwd)
+			ret := (fwd - price) / price * 100
+			// The models only see the filings and amendments known by then
+			known, err := valuationAsOf(vals, filed, price)
+			if err != nil {
+				continue
+			}
+			if err = priceCurrency(ticker, known); err != nil {
+				return nil, err
+			}
+			pit := &valuator{
+				Valuations: map[string]*valuation{ticker: known},
+				tangible:   v.tangible,
+			}
+			for _, model := range scenarioModels {
+				val, _, err := scenarioModelFuncs[model](pit, ticker, s)
+				if err != nil {
+					continue
+				}
+				gap := (val - price) / price * 100
+				b.Obs = append(b.Obs, BacktestObservation{
+					Ticker:        ticker,
+					Date:          Timestamp(filed),
+					Model:         model,
+					Value:         round(val),
+					Price:         price,
+					Gap:           round(gap),
+					ForwardReturn: round(ret),
+					Hit:           (gap > 0) == (ret > 0),
+				})
+			}
+		}
+	}
+	if len(b.Obs) == 0 {
+		return nil, errors.New("No filings with prices over the horizon to backtest")
+	}
+
+	b.Hits = hitRate(b.Obs)
+	for _, model := range scenarioModels {
+		var obs []BacktestObservation
+		for _, o := range b.Obs {
+			if o.Model == model {
+				obs = append(obs, o)
+			}
+		}
+		if len(obs) > 0 {
+			b.Accuracy[model] = hitRate(obs)
+		}
+	}
+	b.Bkts = gapBuckets(b.Obs, opts.GapBuckets)
+	return b, nil
+}
+
+func hitRate(obs []BacktestObservation) float64 {
+	var hits int
+	for _, o := range obs {
+		if o.Hit {
+			hits++
+		}
+	}
+	return percentage(float64(hits) / float64(len(obs)))
+}
+
+// gapBuckets groups the observations between the bounds. The first and last
+// buckets are open ended, bound by the largest float so they can be marshaled
+func gapBuckets(obs []BacktestObservation, bounds []float64) []GapBucket {
+	edges := append([]float64{-math.MaxFloat64}, bounds...)
+	edges = append(edges, math.MaxFloat64)
+	var ret []GapBucket
+	for i := 1; i < len(edges); i++ {
+		bkt := GapBucket{Low: edges[i-1], High: edges[i]}
+		var sum float64
+		for _, o := range obs {
+			if o.Gap >= bkt.Low && o.Gap < bkt.High {
+				bkt.Count++
+				sum += o.ForwardReturn
+			}
+		}
+		if bkt.Count > 0 {
+			bkt.AvgReturn = round(sum / float64(bkt.Count))
+		}
+		ret = append(ret, bkt)
+	}
+	return ret
+}
+
+func (b *backtest) Observations() []BacktestObservation {
+	return b.Obs
+}
+
+func (b *backtest) HitRate() float64 {
+	return b.Hits
+}
+
+func (b *backtest) ModelAccuracy(model ValuationModel) (float64, error) {
+	if acc, ok := b.Accuracy[model]; ok {
+		return acc, nil
+	}
+	return 0, errors.New("No backtest observations for model " + string(model))
+}
+
+func (b *backtest) Buckets() []GapBucket {
+	return b.Bkts
+}
@@ -0,0 +1,378 @@
+package valuator
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"time"
+)
+
+// defaultCurrency is the currency of 10-K filings that do not report one,
+// as 10-K filers report in US dollars. Foreign filers report in any currency
+// so the currency of a 20-F or 40-F is unknown unless reported or set with
+// SetReportingCurrency
+const defaultCurrency = "USD"
+
+// currencyReporter is a filing from a collector that reports its currency
+type currencyReporter interface {
+	Currency() string
+}
+
+// reportedCurrency is the currency reported by the filing or empty if it is
+// unknown
+func reportedCurrency(f ReportedFiling) string {
+	if c, ok := f.(currencyReporter); ok {
+		return strings.ToUpper(c.Currency())
+	}
+	return ""
+}
+
+// currencyName is the code of a currency for the messages
+func currencyName(currency string) string {
+	if currency == "" {
+		return "an unknown currency"
+	}
+	return currency
+}
+
+// isCurrencyCode checks for an ISO 4217 style code, ex: USD, EUR, JPY
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// currencyFiling is a filing in a currency set by the user
+type currencyFiling struct {
+	Filing
+	currency string
+}
+
+func (c *currencyFiling) Currency() string {
+	return c.currency
+}
+
+// priceCurrency checks that the price of a valuation is in the currency of
+// its last filing, as values in one currency are not comparable with a price
+// in another
+func priceCurrency(ticker string, vals *valuation) error {
+	if vals.Pbm == nil || len(vals.FiledData) == 0 {
+		return nil
+	}
+	cur := vals.FiledData[len(vals.FiledData)-1].Currency()
+	if vals.Pbm.Currency() != cur {
+		return errors.New("Price of " + ticker + " in " + vals.Pbm.Currency() + " and filings in " +
+			currencyName(cur) + ". Report in one currency with ReportIn")
+	}
+	return nil
+}
+
+// withDefaultCurrency sets the currency of the filings that have none if one
+// is given. Filings in a known currency are kept in it
+func withDefaultCurrency(fs []Filing, currency string) []Filing {
+	if currency == "" {
+		return fs
+	}
+	ret := make([]Filing, len(fs))
+	for i, f := range fs {
+		ret[i] = f
+		if f.Currency() == "" {
+			ret[i] = &currencyFiling{Filing: f, currency: currency}
+		}
+	}
+	return ret
+}
+
+func (v *valuator) SetReportingCurrency(ticker string, currency string) error {
+	currency = strings.ToUpper(currency)
+	if !isCurrencyCode(currency) {
+		return errors.New("Invalid currency code " + currency)
+	}
+	v.currencies[ticker] = currency
+	return nil
+}
+
+func (v *valuator) SetADRRatio(ticker string, ratio float64) error {
+	if ratio <= 0 {
+		return errors.New("ADR ratio of " + ticker + " should be positive")
+	}
+	v.adrRatios[ticker] = ratio
+	return nil
+}
+
+// sharePrice is the price of an ordinary share from a quoted price of the
+// ticker, which for an ADR is over the ordinary shares it represents
+func (v *valuator) sharePrice(ticker string, price float64) float64 {
+	if r, ok := v.adrRatios[ticker]; ok && price > 0 {
+		return price / r
+	}
+	return price
+}
+
+// currencyView is a valuator with the values of every ticker converted to
+// one currency
+type currencyView struct {
+	*valuator
+	currency string
+}
+
+func (c *currencyView) String() string {
+	data, err := json.MarshalIndent(struct {
+		Currency   string                `json:"Reported In"`
+		Valuations map[string]*valuation `json:"Company"`
+	}{c.currency, c.Valuations}, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling currency view data: ", err)
+	}
+	return string(data)
+}
+
+// Collect is not allowed as the collected data is not converted
+func (c *currencyView) Collect(ticker string) error {
+	return errors.New("Cannot collect " + ticker + " on a view in " + c.currency)
+}
+
+// Write is not allowed so the database keeps the values as reported
+func (c *currencyView) Write() error {
+	return errors.New("Cannot write a view in " + c.currency)
+}
+
+// ReportIn creates a view of the collected tickers in the currency. The
+// filings are converted at the rate on the end of their fiscal period and
+// the market price at the rate on the date it was recorded. Tickers whose
+// filings cannot be converted are left out
+func (v *valuator) ReportIn(currency string, rates FXRateSource) (Valuator, error) {
+	currency = strings.ToUpper(currency)
+	if !isCurrencyCode(currency) {
+		return nil, errors.New("Invalid currency code " + currency)
+	}
+	if rates == nil {
+		return nil, errors.New("No FX rate source to convert to " + currency)
+	}
+	converted, err := v.newView()
+	if err != nil {
+		return nil, err
+	}
+	view := &currencyView{valuator: converted, currency: currency}
+
+	for ticker, vals := range v.Valuations {
+		fs, err := convertFilings(vals.FiledData, currency, rates)
+		if err != nil {
+			log.Println("Leaving out " + ticker + " in " + currency + ": " + err.Error())
+			continue
+		}
+		mea := newMeasures(fs)
+		if err = newYoYs(mea); err != nil {
+			return nil, err
+		}
+		avg, err := newAverages(mea)
+		if err != nil {
+			log.Println("Leaving out " + ticker + " in " + currency + ": " + err.Error())
+			continue
+		}
+
+		// A price with no rate to convert it leaves out the price based metrics
+		var price float64
+		if vals.Pbm != nil && vals.Pbm.Price() > 0 {
+			rate, err := rates.Rate(vals.Pbm.Currency(), currency, time.Time(vals.Date))
+			if err != nil {
+				log.Println("No price for " + ticker + " in " + currency + ": " + err.Error())
+			} else {
+				price = round(vals.Pbm.Price() * rate)
+			}
+		}
+		view.Valuations[ticker] = &valuation{
+			Ticker:    ticker,
+			Date:      vals.Date,
+			FiledData: mea,
+			Avgs:      avg,
+			Pbm:       newPriceBasedMetricsIn(mea[len(mea)-1], avg, price, currency),
+		}
+	}
+	return view, nil
+}
+
+func convertFilings(mea []Measures, currency string, rates FXRateSource) ([]Filing, error) {
+	var ret []Filing
+	for _, m := range mea {
+		f, err := convertFiling(m.Filing(), currency, rates)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, f)
+	}
+	return ret, nil
+}
+
+// scaleReporter is a filing from a collector that reports the scale of its
+// values, ex: 1000 for values in thousands
+type scaleReporter interface {
+	Scale() float64
+}
+
+// inUnits converts the money values of a filing reported in thousands or
+// millions to units so per share values are comparable across filers. The
+// dividend per share and share counts are taken to be reported in units
+func inUnits(f Filing, r ReportedFiling) Filing {
+	if s, ok := r.(scaleReporter); ok && s.Scale() > 0 && s.Scale() != 1 {
+		return &convertedFiling{
+			Filing:         f,
+			currency:       f.Currency(),
+			factor:         s.Scale(),
+			perShareFactor: 1,
+		}
+	}
+	return f
+}
+
+// convertedFiling is a filing with its money values multiplied by a factor,
+// the FX rate to another currency or the scale of the values. Share counts
+// are not converted
+type convertedFiling struct {
+	Filing
+	currency       string
+	factor         float64
+	perShareFactor float64
+}
+
+// convertFiling converts the money values of a filing to the currency at the
+// rate on the end of the fiscal period. Amended filings are converted version
+// by version so the restatements are in the currency too
+func convertFiling(f Filing, currency string, rates FXRateSource) (Filing, error) {
+	if a, ok := f.(*amendedFiling); ok {
+		ret := &amendedFiling{}
+		for _, ver := range a.versions {
+			cv, err := convertFiling(ver, currency, rates)
+			if err != nil {
+				return nil, err
+			}
+			ret.versions = append(ret.versions, cv)
+		}
+		return ret, nil
+	}
+	if f.Currency() == currency {
+		return f, nil
+	}
+	if f.Currency() == "" {
+		return nil, errors.New("Unknown currency of the filing of " + Timestamp(f.FiledOn()).String() + ", set it with SetReportingCurrency")
+	}
+	rate, err := rates.Rate(f.Currency(), currency, f.PeriodEnd())
+	if err != nil {
+		return nil, err
+	}
+	return &convertedFiling{
+		Filing:         f,
+		currency:       currency,
+		factor:         rate,
+		perShareFactor: rate,
+	}, nil
+}
+
+// convert multiplies a money value with the factor of the filing
+func (c *convertedFiling) convert(val float64, err error) (float64, error) {
+	return val * c.factor, err
+}
+
+func (c *convertedFiling) Currency() string {
+	return c.currency
+}
+
+func (c *convertedFiling) Revenue() (float64, error) {
+	return c.convert(c.Filing.Revenue())
+}
+
+func (c *convertedFiling) CostOfRevenue() (float64, error) {
+	return c.convert(c.Filing.CostOfRevenue())
+}
+
+func (c *convertedFiling) GrossMargin() (float64, error) {
+	return c.convert(c.Filing.GrossMargin())
+}
+
+func (c *convertedFiling) OperatingIncome() (float64, error) {
+	return c.convert(c.Filing.OperatingIncome())
+}
+
+func (c *convertedFiling) OperatingExpense() (float64, error) {
+	return c.convert(c.Filing.OperatingExpense())
+}
+
+func (c *convertedFiling) NetIncome() (float64, error) {
+	return c.convert(c.Filing.NetIncome())
+}
+
+func (c *convertedFiling) TotalEquity() (float64, error) {
+	return c.convert(c.Filing.TotalEquity())
+}
+
+func (c *convertedFiling) ShortTermDebt() (float64, error) {
+	return c.convert(c.Filing.ShortTermDebt())
+}
+
+func (c *convertedFiling) LongTermDebt() (float64, error) {
+	return c.convert(c.Filing.LongTermDebt())
+}
+
+func (c *convertedFiling) CurrentLiabilities() (float64, error) {
+	return c.convert(c.Filing.CurrentLiabilities())
+}
+
+func (c *convertedFiling) CurrentAssets() (float64, error) {
+	return c.convert(c.Filing.CurrentAssets())
+}
+
+func (c *convertedFiling) DeferredRevenue() (float64, error) {
+	return c.convert(c.Filing.DeferredRevenue())
+}
+
+func (c *convertedFiling) RetainedEarnings() (float64, error) {
+	return c.convert(c.Filing.RetainedEarnings())
+}
+
+func (c *convertedFiling) OperatingCashFlow() (float64, error) {
+	return c.convert(c.Filing.OperatingCashFlow())
+}
+
+func (c *convertedFiling) CapitalExpenditure() (float64, error) {
+	return c.convert(c.Filing.CapitalExpenditure())
+}
+
+func (c *convertedFiling) Dividend() (float64, error) {
+	return c.convert(c.Filing.Dividend())
+}
+
+func (c *convertedFiling) DividendPerShare() (float64, error) {
+	val, err := c.Filing.DividendPerShare()
+	return val * c.perShareFactor, err
+}
+
+func (c *convertedFiling) Cash() (float64, error) {
+	return c.convert(c.Filing.Cash())
+}
+
+func (c *convertedFiling) Securities() (float64, error) {
+	return c.convert(c.Filing.Securities())
+}
+
+func (c *convertedFiling) Goodwill() (float64, error) {
+	return c.convert(c.Filing.Goodwill())
+}
+
+func (c *convertedFiling) Intangibles() (float64, error) {
+	return c.convert(c.Filing.Intangibles())
+}
+
+func (c *convertedFiling) Assets() (float64, error) {
+	return c.convert(c.Filing.Assets())
+}
+
+func (c *convertedFiling) Liabilities() (float64, error) {
+	return c.convert(c.Filing.Liabilities())
+}
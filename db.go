@@ -26,6 +26,12 @@ type Database interface {
 	Write(string, []byte) error
 }
 
+// TickerLister can be implemented by a Database that can list the tickers it
+// has data for
+type TickerLister interface {
+	Tickers() ([]string, error)
+}
+
 // NewDatabase creates a new database to be used by valuator
 func NewDatabase(url interface{}, ty DatabaseType) (Database, error) {
 	var db Database
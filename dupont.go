@@ -0,0 +1,127 @@
+package valuator
+
+import (
+	"encoding/json"
+	"log"
+)
+
+// DuPont provides an interface to the breakdown of return on equity
+//
+//	3 step: RoE = Net margin * Asset turnover * Equity multiplier
+//	5 step: RoE = Tax & interest burden * Operating margin * Asset turnover * Equity multiplier
+//
+// Filings do not carry pre-tax income so the tax and interest burdens are
+// reported together as net income over operating income
+type DuPont interface {
+	// NetMargin is net income over revenue in %
+	NetMargin() float64
+	AssetTurnover() float64
+	EquityMultiplier() float64
+	// ReturnOnEquity is the product of the 3 step breakdown in %
+	ReturnOnEquity() float64
+	// OperatingMargin is operating income over revenue in %
+	OperatingMargin() float64
+	TaxAndInterestBurden() float64
+	String() string
+}
+
+type dupont struct {
+	Nm     float64 `json:"Net Margin (%)"`
+	At     float64 `json:"Asset Turnover"`
+	Em     float64 `json:"Equity Multiplier"`
+	RoE    float64 `json:"Return on Equity (%)"`
+	Om     float64 `json:"Operating Margin (%)"`
+	Burden float64 `json:"Tax and Interest Burden"`
+}
+
+func (d dupont) String() string {
+	data, err := json.MarshalIndent(d, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling DuPont data: ", err)
+	}
+	return string(data)
+}
+
+// dupontRatios gets the unrounded net margin, asset turnover and equity
+// multiplier of a filing
+func dupontRatios(f Filing) (float64, float64, float64, error) {
+	ni, err := f.NetIncome()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	rev, err := f.Revenue()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	eq, err := f.TotalEquity()
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	as, err := totalAssets(f)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return ni / rev, rev / as, as / eq, nil
+}
+
+func newDuPont(f Filing) *dupont {
+	nm, at, em, err := dupontRatios(f)
+	if err != nil {
+		return nil
+	}
+	ret := &dupont{
+		Nm:  round(nm * 100),
+		At:  round(at),
+		Em:  round(em),
+		RoE: round(nm * at * em * 100),
+	}
+	ni, _ := f.NetIncome()
+	rev, _ := f.Revenue()
+	if oi, err := f.OperatingIncome(); err == nil && oi != 0 {
+		ret.Om = round(oi / rev * 100)
+		ret.Burden = round(ni / oi)
+	}
+	return ret
+}
+
+// dupontAttribution splits the change in RoE (in % points) between two
+// filings into the change from each driver of the 3 step breakdown. The
+// drivers are changed one after the other so the parts add up to the change
+func dupontAttribution(past Filing, current Filing) (float64, float64, float64, error) {
+	nm0, at0, em0, err := dupontRatios(past)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	nm1, at1, em1, err := dupontRatios(current)
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	nm := (nm1 - nm0) * at0 * em0
+	at := nm1 * (at1 - at0) * em0
+	em := nm1 * at1 * (em1 - em0)
+	return round(nm * 100), round(at * 100), round(em * 100), nil
+}
+
+func (d *dupont) NetMargin() float64 {
+	return d.Nm
+}
+
+func (d *dupont) AssetTurnover() float64 {
+	return d.At
+}
+
+func (d *dupont) EquityMultiplier() float64 {
+	return d.Em
+}
+
+func (d *dupont) ReturnOnEquity() float64 {
+	return d.RoE
+}
+
+func (d *dupont) OperatingMargin() float64 {
+	return d.Om
+}
+
+func (d *dupont) TaxAndInterestBurden() float64 {
+	return d.Burden
+}
@@ -4,11 +4,35 @@ import (
 	"errors"
 	"io"
 	"log"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/palafrank/edgar"
 )
 
+// Annual report types of foreign private issuers. 40-F is filed by Canadian
+// issuers under the MJDS and 20-F by all others
+const (
+	filingType20F edgar.FilingType = "20-F"
+	filingType40F edgar.FilingType = "40-F"
+)
+
+// annualFilingTypes are the annual report types collected. A company files
+// one of them in a year but can move between them over the years
+var annualFilingTypes = []edgar.FilingType{
+	edgar.FilingType10K,
+	filingType20F,
+	filingType40F,
+}
+
+// amendmentTypes are the edgar filing types of the amended annual reports
+var amendmentTypes = map[edgar.FilingType]edgar.FilingType{
+	edgar.FilingType10K: "10-K/A",
+	filingType20F:       "20-F/A",
+	filingType40F:       "40-F/A",
+}
+
 type edgarCollector struct {
 	name    string
 	fetcher edgar.FilingFetcher
@@ -31,7 +55,7 @@ func newEdgarCollector(store Store) (Collector, error) {
 func (c *edgarCollector) MapEdgarFilingToValuatorFiling(fs []edgar.Filing) []Filing {
 	var ret []Filing
 	for _, f := range fs {
-		ret = append(ret, f.(Filing))
+		ret = append(ret, newFiscalFiling(f.(ReportedFiling)))
 	}
 	return ret
 }
@@ -54,38 +78,99 @@ func (c *edgarCollector) CollectAnnualData(ticker string,
 	if fp == nil {
 		//If there is no historical data. Get it from Edgar.
 		log.Println("No data found. Fetching from Edgar")
-		cf, err = c.fetcher.CompanyFolder(ticker, edgar.FilingType10K)
+		cf, err = c.fetcher.CompanyFolder(ticker, annualFilingTypes...)
 	} else {
 		// If data available in store use that you create folder
-		cf, err = c.fetcher.CreateFolder(fp, edgar.FilingType10K)
+		cf, err = c.fetcher.CreateFolder(fp, annualFilingTypes...)
 	}
 	if err != nil {
 		return nil, err
 	}
 
-	// Get all the Available filings
-	af := cf.AvailableFilings(edgar.FilingType10K)
-
-	//Filter out based on what was requested
-	var filteredAF []time.Time
-	for _, f := range af {
-		if len(years) > 0 && !contains(f.Year(), years) {
+	// Get all the Available filings of every annual report type
+	var fs []Filing
+	for _, ty := range annualFilingTypes {
+		//Filter out based on what was requested
+		var filteredAF []time.Time
+		for _, f := range cf.AvailableFilings(ty) {
+			if len(years) > 0 && !contains(f.Year(), years) {
+				continue
+			}
+			filteredAF = append(filteredAF, f)
+		}
+		if len(filteredAF) == 0 {
 			continue
 		}
-		filteredAF = append(filteredAF, f)
-	}
 
-	// Get all the financial data
-	if len(filteredAF) > 0 {
-		fils, err := cf.Filings(edgar.FilingType10K, filteredAF...)
+		// Get all the financial data
+		fils, err := cf.Filings(ty, filteredAF...)
 		if err != nil {
 			return nil, err
 		}
+		tfs := c.MapEdgarFilingToValuatorFiling(fils)
+		if ty == edgar.FilingType10K {
+			tfs = withDefaultCurrency(tfs, defaultCurrency)
+		}
+		fs = append(fs, tfs...)
+	}
+	if len(fs) == 0 {
+		return nil, errors.New("No filings collected")
+	}
+
+	fs = latestByFiscalYear(fs)
+	ret := fs
+	for _, ty := range annualFilingTypes {
+		ret = append(ret, c.amendments(cf, amendmentTypes[ty], fs)...)
+	}
+	return ret, nil
+}
 
-		return c.MapEdgarFilingToValuatorFiling(fils), nil
+// latestByFiscalYear merges the filings of the annual report types into one
+// filing per fiscal year, oldest first. A fiscal year filed on more than one
+// type is taken from the latest filing
+func latestByFiscalYear(fs []Filing) []Filing {
+	byYear := make(map[int]Filing)
+	var years []int
+	for _, f := range fs {
+		y := f.FiscalYear()
+		o, ok := byYear[y]
+		if !ok {
+			years = append(years, y)
+		} else {
+			log.Println("Fiscal year " + strconv.Itoa(y) + " of " + f.Ticker() + " is filed more than once, using the latest filing")
+			if o.FiledOn().After(f.FiledOn()) {
+				continue
+			}
+		}
+		byYear[y] = f
 	}
+	sort.Ints(years)
+	ret := make([]Filing, len(years))
+	for i, y := range years {
+		ret[i] = byYear[y]
+	}
+	return ret
+}
 
-	return nil, errors.New("No filings collected")
+// amendments gets the amended annual reports, ex: 10-K/A, that amend the
+// collected filings. Not every folder has them so there are none on error
+func (c *edgarCollector) amendments(cf edgar.CompanyFolder, ty edgar.FilingType, fs []Filing) []Filing {
+	af := cf.AvailableFilings(ty)
+	if len(af) == 0 {
+		return nil
+	}
+	fils, err := cf.Filings(ty, af...)
+	if err != nil {
+		log.Println("Error collecting amended filings: ", err.Error())
+		return nil
+	}
+	var ret []Filing
+	for _, f := range fils {
+		if a := newAmendment(fs, f.(ReportedFiling)); a != nil {
+			ret = append(ret, a)
+		}
+	}
+	return ret
 }
 
 func (c *edgarCollector) Write(ticker string, writer io.Writer) error {
@@ -0,0 +1,130 @@
+package valuator
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+)
+
+// EarningsPower provides an interface to the Earnings Power Value (EPV)
+type EarningsPower interface {
+	// NormalizedMargin is the average operating margin (%) over the years
+	NormalizedMargin() float64
+	// NormalizedEarnings is the after-tax operating income at the normalized
+	// margin less maintenance capex
+	NormalizedEarnings() float64
+	MaintenanceCapex() float64
+	ExcessCash() float64
+	// PerShare is the EPV of the equity per share
+	PerShare() float64
+	// GrowthValue is the DCF value per share over and above the EPV
+	GrowthValue() float64
+	String() string
+}
+
+type epv struct {
+	Margin   float64 `json:"Normalized Operating Margin (%)"`
+	Earnings float64 `json:"Normalized Earnings"`
+	Capex    float64 `json:"Maintenance Capex"`
+	Cash     float64 `json:"Excess Cash"`
+	Value    float64 `json:"EPV Per Share"`
+	Growth   float64 `json:"Growth Value"`
+}
+
+func (e epv) String() string {
+	data, err := json.MarshalIndent(e, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling EPV data: ", err)
+	}
+	return string(data)
+}
+
+// EarningsPowerValue is the value of the current earnings assuming no growth
+//
+//	Earnings = Revenue * avg operating margin * (1 - tax) - maintenance capex
+//	EPV = Earnings/dr + excess cash - debt
+//
+// The growth value is what the DCF adds to the EPV
+func (v *valuator) EarningsPowerValue(ticker string, dr float64, taxRate float64, maintenance float64, duration int, endYear ...int) (EarningsPower, error) {
+
+	if len(endYear) > 1 {
+		return nil, errors.New("Specify only one end year for EPV calculation")
+	}
+	if dr <= 0 {
+		return nil, errors.New("EPV needs a positive discount rate")
+	}
+	vals, ok := v.Valuations[ticker]
+	if !ok {
+		return nil, errors.New("Valuator has not be told to collect data on " + ticker)
+	}
+	meas := vals.FiledData
+	if len(endYear) == 1 {
+		meas = createMeasuresList(vals.FiledData, endYear[0])
+	}
+	if len(meas) == 0 {
+		return nil, errors.New("No measures available for " + ticker)
+	}
+
+	// Normalize the margin and capex over all the collected years
+	var margin, capex float64
+	for _, m := range meas {
+		margin += m.OpsMargin()
+		cx, _ := m.Filing().CapitalExpenditure()
+		capex += math.Abs(cx)
+	}
+	ret := &epv{
+		Margin: avgCalc(margin, float64(len(meas))),
+		Capex:  round(capex / float64(len(meas)) * (maintenance / 100)),
+	}
+
+	last := meas[len(meas)-1].Filing()
+	rev, err := last.Revenue()
+	if err != nil {
+		return nil, err
+	}
+	sc, err := last.ShareCount()
+	if err != nil {
+		return nil, err
+	}
+	cash, _ := last.Cash()
+	sec, _ := last.Securities()
+	ld, _ := last.LongTermDebt()
+	sd, _ := last.ShortTermDebt()
+	ret.Cash = cash + sec
+
+	ret.Earnings = round(rev*(ret.Margin/100)*(1-taxRate/100) - ret.Capex)
+	ret.Value = round((ret.Earnings/(dr/100) + ret.Cash - ld - sd) / sc)
+
+	dcf, err := v.DiscountedFCFTrend(ticker, dr, 100, duration, endYear...)
+	if err != nil {
+		return nil, err
+	}
+	ret.Growth = round(dcf - ret.Value)
+
+	return ret, nil
+}
+
+func (e *epv) NormalizedMargin() float64 {
+	return e.Margin
+}
+
+func (e *epv) NormalizedEarnings() float64 {
+	return e.Earnings
+}
+
+func (e *epv) MaintenanceCapex() float64 {
+	return e.Capex
+}
+
+func (e *epv) ExcessCash() float64 {
+	return e.Cash
+}
+
+func (e *epv) PerShare() float64 {
+	return e.Value
+}
+
+func (e *epv) GrowthValue() float64 {
+	return e.Growth
+}
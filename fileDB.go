@@ -7,6 +7,7 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"strings"
 )
 
 /*
@@ -74,3 +75,17 @@ func (f *fileDB) Write(ticker string, data []byte) error {
 	f.writer[ticker].Write(data)
 	return nil
 }
+
+func (f *fileDB) Tickers() ([]string, error) {
+	files, err := ioutil.ReadDir(f.path)
+	if err != nil {
+		return nil, err
+	}
+	var tickers []string
+	for _, file := range files {
+		if !file.IsDir() && strings.HasSuffix(file.Name(), ".json") {
+			tickers = append(tickers, strings.TrimSuffix(file.Name(), ".json"))
+		}
+	}
+	return tickers, nil
+}
@@ -0,0 +1,96 @@
+package valuator
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+// fiscalYearEndMonth is the month from which a fiscal year is named by the
+// calendar year it ends in. Fiscal years ending before it are named by the
+// year before, ex: a retailer's year ending in January 2018 is fiscal 2017
+const fiscalYearEndMonth = time.June
+
+// fiscalReportLag is the number of months between the end of the fiscal year
+// and the filing of the annual report, which is due within 60 days for large
+// filers. It is used when the filing does not report its fiscal period
+const fiscalReportLag = 2
+
+// periodReporter is a filing that reports the document period end date and
+// the fiscal year focus from its document and entity information
+type periodReporter interface {
+	DocumentPeriodEndDate() (time.Time, error)
+	DocumentFiscalYearFocus() (int, error)
+}
+
+// fiscalFiling adds the fiscal period and currency to a filing that does not
+// report them
+type fiscalFiling struct {
+	ReportedFiling
+	year     int
+	end      time.Time
+	currency string
+}
+
+// newFiscalFiling adds the fiscal period reported by a filing. When it is
+// not reported, the period is estimated as ending on the last day of the
+// month fiscalReportLag months before the filing month. Filings that report
+// their fiscal period are used as they are. Values reported in thousands or
+// millions are converted to units
+func newFiscalFiling(f ReportedFiling) Filing {
+	if ff, ok := f.(Filing); ok {
+		return inUnits(ff, f)
+	}
+	end, year, err := reportedPeriod(f)
+	if err != nil {
+		filed := f.FiledOn()
+		log.Println("Estimating the fiscal period of "+f.Ticker()+" filed on "+Timestamp(filed).String()+": ", err)
+		// Day 0 of a month is the last day of the month before
+		end = time.Date(filed.Year(), filed.Month()-fiscalReportLag+1, 0, 0, 0, 0, 0, time.UTC)
+		year = fiscalYear(end)
+	}
+	return inUnits(&fiscalFiling{
+		ReportedFiling: f,
+		year:           year,
+		end:            end,
+		currency:       reportedCurrency(f),
+	}, f)
+}
+
+// reportedPeriod gets the period end and fiscal year reported by a filing.
+// The fiscal year is named by the period end when the focus is not reported
+func reportedPeriod(f ReportedFiling) (time.Time, int, error) {
+	pr, ok := f.(periodReporter)
+	if !ok {
+		return time.Time{}, 0, errors.New("No fiscal period reported")
+	}
+	end, err := pr.DocumentPeriodEndDate()
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	year, err := pr.DocumentFiscalYearFocus()
+	if err != nil || year <= 0 {
+		year = fiscalYear(end)
+	}
+	return end, year, nil
+}
+
+// fiscalYear names the fiscal year ending on the date
+func fiscalYear(end time.Time) int {
+	if end.Month() < fiscalYearEndMonth {
+		return end.Year() - 1
+	}
+	return end.Year()
+}
+
+func (f *fiscalFiling) FiscalYear() int {
+	return f.year
+}
+
+func (f *fiscalFiling) PeriodEnd() time.Time {
+	return f.end
+}
+
+func (f *fiscalFiling) Currency() string {
+	return f.currency
+}
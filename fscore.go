@@ -0,0 +1,146 @@
+package valuator
+
+import (
+	"encoding/json"
+	"log"
+)
+
+// FScore provides an interface to the Piotroski F-score of a filing against
+// the filing of the past year. Each signal passes (true) or fails (false)
+type FScore interface {
+	// Score is the number of signals that pass (0-9)
+	Score() int
+	PositiveReturnOnAssets() bool
+	PositiveCashFlow() bool
+	ImprovingReturnOnAssets() bool
+	CashFlowAboveEarnings() bool
+	LowerLeverage() bool
+	ImprovingCurrentRatio() bool
+	NoDilution() bool
+	ImprovingGrossMargin() bool
+	ImprovingAssetTurnover() bool
+	String() string
+}
+
+type fscore struct {
+	Total     int  `json:"Score"`
+	Roa       bool `json:"Positive RoA"`
+	Cf        bool `json:"Positive Cash Flow"`
+	DeltaRoa  bool `json:"Improving RoA"`
+	Accrual   bool `json:"Cash Flow Above Earnings"`
+	Leverage  bool `json:"Lower Leverage"`
+	Liquidity bool `json:"Improving Current Ratio"`
+	Shares    bool `json:"No Dilution"`
+	Margin    bool `json:"Improving Gross Margin"`
+	Turnover  bool `json:"Improving Asset Turnover"`
+}
+
+func (f fscore) String() string {
+	data, err := json.MarshalIndent(f, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling F-score data: ", err)
+	}
+	return string(data)
+}
+
+func grossMarginRatio(f Filing) float64 {
+	gm, err := f.GrossMargin()
+	if err != nil {
+		return 0
+	}
+	rev, err := f.Revenue()
+	if err != nil {
+		return 0
+	}
+	return gm / rev
+}
+
+func assetTurnover(f Filing) float64 {
+	rev, err := f.Revenue()
+	if err != nil {
+		return 0
+	}
+	as, err := f.Assets()
+	if err != nil {
+		return 0
+	}
+	return rev / as
+}
+
+// newFScore computes the nine Piotroski signals from two consecutive filings
+//
+//	Profitability: RoA > 0, OCF > 0, RoA rising, OCF > Net income
+//	Leverage/Liquidity: Leverage falling, current ratio rising, no new shares
+//	Efficiency: Gross margin rising, asset turnover rising
+//
+// Leverage is taken as the financial leverage (debt to equity) measure.
+// Signals that need total assets fail when the assets were not collected.
+func newFScore(pastMeasure Measures, currentMeasure Measures) *fscore {
+	ret := new(fscore)
+	past := pastMeasure.Filing()
+	current := currentMeasure.Filing()
+
+	ni, _ := current.NetIncome()
+	ocf, _ := current.OperatingCashFlow()
+	ret.Roa = ni > 0
+	ret.Cf = ocf > 0
+	ret.DeltaRoa = currentMeasure.ReturnOnAssets() > pastMeasure.ReturnOnAssets()
+	ret.Accrual = ocf > ni
+
+	ret.Leverage = currentMeasure.FinancialLeverage() < pastMeasure.FinancialLeverage()
+	ret.Liquidity = currentMeasure.CurrentRatio() > pastMeasure.CurrentRatio()
+	ps, perr := past.ShareCount()
+	cs, cerr := current.ShareCount()
+	ret.Shares = perr == nil && cerr == nil && cs <= ps
+
+	ret.Margin = grossMarginRatio(current) > grossMarginRatio(past)
+	ret.Turnover = assetTurnover(current) > assetTurnover(past)
+
+	for _, signal := range []bool{ret.Roa, ret.Cf, ret.DeltaRoa, ret.Accrual,
+		ret.Leverage, ret.Liquidity, ret.Shares, ret.Margin, ret.Turnover} {
+		if signal {
+			ret.Total++
+		}
+	}
+	return ret
+}
+
+func (f *fscore) Score() int {
+	return f.Total
+}
+
+func (f *fscore) PositiveReturnOnAssets() bool {
+	return f.Roa
+}
+
+func (f *fscore) PositiveCashFlow() bool {
+	return f.Cf
+}
+
+func (f *fscore) ImprovingReturnOnAssets() bool {
+	return f.DeltaRoa
+}
+
+func (f *fscore) CashFlowAboveEarnings() bool {
+	return f.Accrual
+}
+
+func (f *fscore) LowerLeverage() bool {
+	return f.Leverage
+}
+
+func (f *fscore) ImprovingCurrentRatio() bool {
+	return f.Liquidity
+}
+
+func (f *fscore) NoDilution() bool {
+	return f.Shares
+}
+
+func (f *fscore) ImprovingGrossMargin() bool {
+	return f.Margin
+}
+
+func (f *fscore) ImprovingAssetTurnover() bool {
+	return f.Turnover
+}
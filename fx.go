@@ -0,0 +1,129 @@
+package valuator
+
+import (
+	"encoding/csv"
+	"errors"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FXRateSourceType is a type definition of the different FX rate sources
+type FXRateSourceType string
+
+// CSVFXRateSourceType is a CSV file of FX rates by date
+const CSVFXRateSourceType FXRateSourceType = "csv"
+
+// FXRateSource is an interface to the source of historical FX rates
+type FXRateSource interface {
+	// Name of the FX rate source
+	Name() string
+	// Rate is the units of the to currency for a unit of the from currency on
+	// the date or the last rate in the week before it
+	Rate(from string, to string, date time.Time) (float64, error)
+}
+
+// NewFXRateSource creates a source of historical FX rates
+func NewFXRateSource(url interface{}, ty FXRateSourceType) (FXRateSource, error) {
+	switch ty {
+	case CSVFXRateSourceType:
+		if path, ok := url.(string); ok {
+			return newCSVFXRateSource(path)
+		}
+		return nil, errors.New("CSV FX rate source needs a file path")
+	default:
+	}
+	log.Println("Unknown FX rate source type ", ty)
+	return nil, errors.New("Unsupported FX rate source " + string(ty))
+}
+
+// csvFXRateSource reads FX rates from a CSV file. Every row has a date
+// (YYYY-MM-DD), the from and to currency and the rate, ex:
+// 2017-07-31,EUR,USD,1.1842. A header row is skipped. Rates are used in
+// both directions and crossed through USD when there is no direct rate
+type csvFXRateSource struct {
+	rates map[string][]PricePoint
+}
+
+func fxPair(from string, to string) string {
+	return from + "/" + to
+}
+
+func newCSVFXRateSource(path string) (FXRateSource, error) {
+	fd, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer fd.Close()
+	records, err := csv.NewReader(fd).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	c := &csvFXRateSource{rates: make(map[string][]PricePoint)}
+	for i, rec := range records {
+		if len(rec) < 4 {
+			return nil, errors.New("FX rates need a date, two currencies and a rate on every row")
+		}
+		rate, err := strconv.ParseFloat(strings.TrimSpace(rec[3]), 64)
+		if err != nil {
+			if i == 0 {
+				continue
+			}
+			return nil, err
+		}
+		if rate <= 0 {
+			return nil, errors.New("FX rate should be positive on " + rec[0])
+		}
+		from := strings.ToUpper(strings.TrimSpace(rec[1]))
+		to := strings.ToUpper(strings.TrimSpace(rec[2]))
+		if !isCurrencyCode(from) || !isCurrencyCode(to) {
+			return nil, errors.New("Invalid currency code in FX rates on " + rec[0])
+		}
+		date := getDate(strings.TrimSpace(rec[0]))
+		c.rates[fxPair(from, to)] = append(c.rates[fxPair(from, to)], PricePoint{Date: date, Close: rate})
+		c.rates[fxPair(to, from)] = append(c.rates[fxPair(to, from)], PricePoint{Date: date, Close: 1 / rate})
+	}
+	for _, r := range c.rates {
+		sort.Slice(r, func(i, j int) bool {
+			return r[i].Date.String() < r[j].Date.String()
+		})
+	}
+	return c, nil
+}
+
+func (c *csvFXRateSource) Name() string {
+	return "CSV FX Rate Source"
+}
+
+func (c *csvFXRateSource) Rate(from string, to string, date time.Time) (float64, error) {
+	if from == to {
+		return 1, nil
+	}
+	if r, ok := c.rates[fxPair(from, to)]; ok {
+		return c.rateOn(from, to, r, date)
+	}
+	if from != defaultCurrency && to != defaultCurrency {
+		f, err := c.Rate(from, defaultCurrency, date)
+		if err != nil {
+			return 0, err
+		}
+		t, err := c.Rate(defaultCurrency, to, date)
+		if err != nil {
+			return 0, err
+		}
+		return f * t, nil
+	}
+	return 0, errors.New("No FX rate for " + fxPair(from, to))
+}
+
+// rateOn is the rate on the date with the same lag allowed as for closes
+func (c *csvFXRateSource) rateOn(from string, to string, rates []PricePoint, date time.Time) (float64, error) {
+	rate, err := closeOn(rates, date)
+	if err != nil {
+		return 0, errors.New("No " + fxPair(from, to) + " rate on or close before " + getDateString(date))
+	}
+	return rate, nil
+}
@@ -0,0 +1,187 @@
+package valuator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+)
+
+var (
+	// Bounds (in %) within which the implied rates are searched for
+	impliedGrowthBounds   = [2]float64{-100, 100}
+	impliedDiscountBounds = [2]float64{0.01, 100}
+	impliedTolerance      = 0.01
+	impliedMaxIterations  = 100
+)
+
+// ImpliedRate provides an interface to the result of a reverse DCF
+type ImpliedRate interface {
+	// Rate is the solved rate in % that equates intrinsic value to price
+	Rate() float64
+	// Price is the market price that was solved against
+	Price() float64
+	// IntrinsicValue is the DCF value at the solved rate
+	IntrinsicValue() float64
+	// Iterations taken by the solver
+	Iterations() int
+	// Converged is true if the DCF value is within tolerance of the price
+	Converged() bool
+	String() string
+}
+
+type implied struct {
+	Solved    float64 `json:"Implied Rate (%)"`
+	Target    float64 `json:"Market Price"`
+	Intrinsic float64 `json:"Intrinsic Value"`
+	Iters     int     `json:"Iterations"`
+	Conv      bool    `json:"Converged"`
+}
+
+func (i implied) String() string {
+	data, err := json.MarshalIndent(i, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling implied rate data: ", err)
+	}
+	return string(data)
+}
+
+// solveImplied does a bisection search for the rate within [lo, hi] at which
+// fn(rate) equals the target price. fn is expected to be monotonic within the
+// bounds. An error is returned if the target is not bracketed by the bounds.
+func solveImplied(target float64, lo float64, hi float64,
+	fn func(float64) (float64, error)) (*implied, error) {
+
+	fLo, err := fn(lo)
+	if err != nil {
+		return nil, err
+	}
+	fHi, err := fn(hi)
+	if err != nil {
+		return nil, err
+	}
+	if (fLo-target)*(fHi-target) > 0 {
+		return nil, fmt.Errorf("No solution for price %.2f between %.2f%% and %.2f%%",
+			target, lo, hi)
+	}
+
+	ret := &implied{Target: target}
+	for ret.Iters = 1; ret.Iters <= impliedMaxIterations; ret.Iters++ {
+		mid := (lo + hi) / 2
+		fMid, err := fn(mid)
+		if err != nil {
+			return nil, err
+		}
+		ret.Solved = mid
+		ret.Intrinsic = fMid
+		if math.Abs(fMid-target) <= impliedTolerance {
+			ret.Conv = true
+			break
+		}
+		if (fLo-target)*(fMid-target) > 0 {
+			lo, fLo = mid, fMid
+		} else {
+			hi = mid
+		}
+	}
+	if ret.Iters > impliedMaxIterations {
+		ret.Iters = impliedMaxIterations
+	}
+	ret.Solved = round(ret.Solved)
+	return ret, nil
+}
+
+func (v *valuator) marketPrice(ticker string) (float64, error) {
+	vals, ok := v.Valuations[ticker]
+	if !ok {
+		return 0, errors.New("Valuator has not be told to collect data on " + ticker)
+	}
+	if err := priceCurrency(ticker, vals); err != nil {
+		return 0, err
+	}
+	if vals.Pbm == nil || vals.Pbm.Price() <= 0 {
+		return 0, errors.New("No market price available for " + ticker)
+	}
+	return vals.Pbm.Price(), nil
+}
+
+func (v *valuator) ImpliedGrowth(ticker string, dr float64, duration int, endYear ...int) (ImpliedRate, error) {
+	price, err := v.marketPrice(ticker)
+	if err != nil {
+		return nil, err
+	}
+	return v.impliedGrowth(ticker, price, dr, duration, endYear...)
+}
+
+func (v *valuator) ImpliedDiscountRate(ticker string, trend float64, duration int, endYear ...int) (ImpliedRate, error) {
+	price, err := v.marketPrice(ticker)
+	if err != nil {
+		return nil, err
+	}
+	return v.impliedDiscountRate(ticker, price, trend, duration, endYear...)
+}
+
+func (v *valuator) impliedGrowth(ticker string, price float64, dr float64, duration int, endYear ...int) (ImpliedRate, error) {
+
+	if len(endYear) > 1 {
+		return nil, errors.New("Specify only one end year for DCF calculation")
+	}
+	vals, ok := v.Valuations[ticker]
+	if !ok {
+		return nil, errors.New("Valuator has not be told to collect data on " + ticker)
+	}
+	div := vals.Avgs.AvgDividendGrowth()
+	bv := vals.FiledData[len(vals.FiledData)-1].BookValue()
+
+	if len(endYear) == 1 {
+		meas := createMeasuresList(vals.FiledData, endYear[0])
+		if avgs, err := newAverages(meas); err == nil {
+			div = avgs.AvgDividendGrowth()
+			bv = meas[len(meas)-1].BookValue()
+		} else {
+			return nil, err
+		}
+	}
+
+	// Same as DiscountedFCFTrend with the FCF growth as the unknown
+	ret, err := solveImplied(price, impliedGrowthBounds[0], impliedGrowthBounds[1],
+		func(growth float64) (float64, error) {
+			return v.DiscountedCashFlow(ticker, dr, bv*(growth/100), div, duration, endYear...)
+		})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (v *valuator) impliedDiscountRate(ticker string, price float64, trend float64, duration int, endYear ...int) (ImpliedRate, error) {
+	ret, err := solveImplied(price, impliedDiscountBounds[0], impliedDiscountBounds[1],
+		func(dr float64) (float64, error) {
+			return v.DiscountedFCFTrend(ticker, dr, trend, duration, endYear...)
+		})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (i *implied) Rate() float64 {
+	return i.Solved
+}
+
+func (i *implied) Price() float64 {
+	return i.Target
+}
+
+func (i *implied) IntrinsicValue() float64 {
+	return i.Intrinsic
+}
+
+func (i *implied) Iterations() int {
+	return i.Iters
+}
+
+func (i *implied) Converged() bool {
+	return i.Conv
+}
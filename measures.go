@@ -3,13 +3,19 @@ package valuator
 import (
 	"encoding/json"
 	"log"
+	"math"
 	"sort"
 )
 
 // Measures provides and interface to the computed mesaures from a filing
 type Measures interface {
 	Filing() Filing
+	AsFiled() Filing
+	Restatements() []Restatement
 	FiledOn() string
+	FiscalYear() int
+	PeriodEnd() string
+	Currency() string
 	NewYoy(Measures) error
 	Yoy() Yoy
 	BookValue() float64
@@ -24,12 +30,28 @@ type Measures interface {
 	PayOutToFcf() float64
 	WorkingCapital() float64
 	CurrentRatio() float64
+	OwnerEarnings() float64
+	TangibleBookValue() float64
+	ReturnOnTangibleEquity() float64
+	GoodwillToEquity() float64
+	InvestedCapital() float64
+	// PreTaxReturnOnInvestedCapital is the operating income over the
+	// invested capital in % as the filings do not carry the tax expense
+	PreTaxReturnOnInvestedCapital() float64
+	// ReturnOnInvestedCapital is the RoIC (%) after the tax rate in %
+	ReturnOnInvestedCapital(taxRate float64) float64
+	PerShare(basis ShareBasis) PerShare
+	FScore() FScore
+	AltmanZ() AltmanZ
+	DuPont() DuPont
 	String() string
 }
 
 type measures struct {
 	filing     Filing
-	Year       int       `json:"-"`
+	Year       int       `json:"Fiscal Year"`
+	End        Timestamp `json:"Period End"`
+	Cur        string    `json:"Currency"`
 	Date       Timestamp `json:"Date"`
 	Bv         float64   `json:"Book Value"`
 	Cm         float64   `json:"Contribution Margin"`
@@ -43,7 +65,20 @@ type measures struct {
 	DivToFcf   float64   `json:"Dividend to FCF"`
 	Wc         float64   `json:"Working Capital"`
 	Cr         float64   `json:"Current Ratio"`
+	Oe         float64   `json:"Owner Earnings"`
+	Tbv        float64   `json:"Tangible Book Value"`
+	RoTE       float64   `json:"Return on Tangible Equity (%)"`
+	GwToEq     float64   `json:"Goodwill to Equity (%)"`
+	Ic         float64   `json:"Invested Capital"`
+	RoIC       float64   `json:"Pre-tax Return on Invested Capital (%)"`
 	YearOnYear *yoy      `json:"YoY"`
+	Fs         *fscore   `json:"Piotroski F-Score"`
+	Az         *altmanZ  `json:"Altman Z"`
+	Dp         *dupont   `json:"DuPont"`
+	Ps         perShares `json:"Per Share"`
+
+	// Values of the original filing changed by its amendments
+	Rs []Restatement `json:"Restatements,omitempty"`
 }
 
 func (m measures) String() string {
@@ -60,11 +95,18 @@ func newMeasures(fs []Filing) []Measures {
 		m := new(measures)
 		m.filing = f
 		m.Date = Timestamp(f.FiledOn())
+		m.Year = f.FiscalYear()
+		m.End = Timestamp(f.PeriodEnd())
+		m.Cur = f.Currency()
+		m.Rs = restatements(f)
 		m.collect()
 		ms = append(ms, m)
 	}
 	// Sort all the measures that was calculated for YoY computation
-	sort.Slice(ms, func(i, j int) bool {
+	sort.SliceStable(ms, func(i, j int) bool {
+		if ms[i].FiscalYear() != ms[j].FiscalYear() {
+			return ms[i].FiscalYear() < ms[j].FiscalYear()
+		}
 		return ms[i].FiledOn() < ms[j].FiledOn()
 	})
 
@@ -75,7 +117,42 @@ func (m *measures) Yoy() Yoy {
 	return m.YearOnYear
 }
 
+// AltmanZ uses book equity in place of market value as there is no price
+// for the filing. It is nil if the balance sheet totals are not known
+func (m *measures) AltmanZ() AltmanZ {
+	if m.Az == nil {
+		return nil
+	}
+	return m.Az
+}
+
+// DuPont is nil if the filing does not have the data for the breakdown
+func (m *measures) DuPont() DuPont {
+	if m.Dp == nil {
+		return nil
+	}
+	return m.Dp
+}
+
+// PerShare is nil if the filing does not have the shares on the basis
+func (m *measures) PerShare(basis ShareBasis) PerShare {
+	if ps, ok := m.Ps[basis]; ok {
+		return ps
+	}
+	return nil
+}
+
+// FScore is nil for the first filing as it has no past year to compare with
+func (m *measures) FScore() FScore {
+	if m.Fs == nil {
+		return nil
+	}
+	return m.Fs
+}
+
 func (m *measures) NewYoy(past Measures) error {
+	m.Fs = newFScore(past, m)
+
 	yoy, err := newYoy(past, m)
 	if err == nil {
 		m.YearOnYear = yoy
@@ -96,12 +173,26 @@ func (m *measures) collect() {
 	m.RoE = m.ReturnOnEquity()
 	m.Wc = m.WorkingCapital()
 	m.Cr = m.CurrentRatio()
+	m.Oe = m.OwnerEarnings()
+	m.Tbv = m.TangibleBookValue()
+	m.RoTE = m.ReturnOnTangibleEquity()
+	m.GwToEq = m.GoodwillToEquity()
+	m.Ic = m.InvestedCapital()
+	m.RoIC = m.PreTaxReturnOnInvestedCapital()
+	m.Az = newAltmanZ(m, 0)
+	m.Dp = newDuPont(m.filing)
+	m.Ps = make(perShares)
+	for _, basis := range shareBases {
+		if ps := newPerShare(m, basis); ps != nil {
+			m.Ps[basis] = ps
+		}
+	}
 }
 
 func createMeasuresList(measures []Measures, endYear int) []Measures {
 	var ret []Measures
 	for _, mea := range measures {
-		if getYear(mea.FiledOn()) <= endYear {
+		if mea.FiscalYear() <= endYear {
 			ret = append(ret, mea)
 		}
 	}
@@ -112,10 +203,32 @@ func (m *measures) FiledOn() string {
 	return Timestamp(m.filing.FiledOn()).String()
 }
 
+func (m *measures) FiscalYear() int {
+	return m.Year
+}
+
+func (m *measures) PeriodEnd() string {
+	return m.End.String()
+}
+
+func (m *measures) Currency() string {
+	return m.Cur
+}
+
 func (m *measures) Filing() Filing {
 	return m.filing
 }
 
+// AsFiled is the filing before any amendments
+func (m *measures) AsFiled() Filing {
+	return originalFiling(m.filing)
+}
+
+// Restatements is empty if the filing was not amended or nothing changed
+func (m *measures) Restatements() []Restatement {
+	return m.Rs
+}
+
 /*
  BookValue:
     Value of the company retained within the equity portion of the BS
@@ -260,3 +373,117 @@ func (m *measures) CurrentRatio() float64 {
 	}
 	return round(assets / liab)
 }
+
+/*
+ OwnerEarnings:
+    Cash that can be taken out of the business by the owners (Buffett)
+		OE = Net income + non-cash charges - maintenance capex - change in WC
+		The filings do not break out D&A so the operating cash flow is used,
+		which already has the non-cash charges and the change in WC.
+		All of capex is treated as maintenance capex.
+*/
+func (m *measures) OwnerEarnings() float64 {
+	ocf, err := m.filing.OperatingCashFlow()
+	if err != nil {
+		return 0
+	}
+	capex, _ := m.filing.CapitalExpenditure()
+	return ocf - math.Abs(capex)
+}
+
+// tangibleEquity is the total equity less goodwill and intangibles
+func tangibleEquity(f Filing) (float64, error) {
+	eq, err := f.TotalEquity()
+	if err != nil {
+		return 0, err
+	}
+	gw, _ := f.Goodwill()
+	intan, _ := f.Intangibles()
+	return eq - gw - intan, nil
+}
+
+/*
+ TangibleBookValue:
+    Book value without the goodwill and intangibles from acquisitions
+		TBV = (TotalEquity - Goodwill - Intangibles)/Total share count
+*/
+func (m *measures) TangibleBookValue() float64 {
+	teq, err := tangibleEquity(m.filing)
+	if err != nil {
+		return 0
+	}
+	sc, err := m.filing.ShareCount()
+	if err != nil {
+		return 0
+	}
+	return round(teq / sc)
+}
+
+func (m *measures) ReturnOnTangibleEquity() float64 {
+	ni, err := m.filing.NetIncome()
+	if err != nil {
+		return 0
+	}
+	teq, err := tangibleEquity(m.filing)
+	if err != nil || teq == 0 {
+		return 0
+	}
+	return percentage(ni / teq)
+}
+
+func (m *measures) GoodwillToEquity() float64 {
+	gw, err := m.filing.Goodwill()
+	if err != nil {
+		return 0
+	}
+	eq, err := m.filing.TotalEquity()
+	if err != nil {
+		return 0
+	}
+	return percentage(gw / eq)
+}
+
+// investedCapital is the equity and debt in the business net of cash
+func investedCapital(f Filing) (float64, error) {
+	eq, err := f.TotalEquity()
+	if err != nil {
+		return 0, err
+	}
+	// Ignore errors as debt and cash could be 0
+	ld, _ := f.LongTermDebt()
+	sd, _ := f.ShortTermDebt()
+	cash, _ := f.Cash()
+	return eq + ld + sd - cash, nil
+}
+
+func (m *measures) InvestedCapital() float64 {
+	ic, err := investedCapital(m.filing)
+	if err != nil {
+		return 0
+	}
+	return ic
+}
+
+/*
+ ReturnOnInvestedCapital:
+    Return on the capital put in by the owners and lenders
+		RoIC = Operating income * (1 - tax)/Invested capital
+		Invested capital = TotalEquity + LTD + STD - Cash
+    The filings do not carry the tax expense so the tax rate is an input,
+    ex: the one used for the WACC. The pre-tax RoIC is kept in the measures
+*/
+func (m *measures) ReturnOnInvestedCapital(taxRate float64) float64 {
+	oi, err := m.filing.OperatingIncome()
+	if err != nil {
+		return 0
+	}
+	ic, err := investedCapital(m.filing)
+	if err != nil || ic <= 0 {
+		return 0
+	}
+	return percentage(oi * (1 - taxRate/100) / ic)
+}
+
+func (m *measures) PreTaxReturnOnInvestedCapital() float64 {
+	return m.ReturnOnInvestedCapital(0)
+}
@@ -0,0 +1,172 @@
+package valuator
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+)
+
+// Simulation provides an interface to the distribution of simulated DCF values
+type Simulation interface {
+	Runs() int
+	Mean() float64
+	// Percentile returns the pth percentile (0-100) of the simulated values
+	Percentile(p float64) float64
+	// Price is the market price the simulation was compared against
+	Price() float64
+	// ProbabilityAbovePrice is the % of runs with intrinsic value above price
+	ProbabilityAbovePrice() float64
+	String() string
+}
+
+type simulation struct {
+	values []float64
+	N      int     `json:"Runs"`
+	Avg    float64 `json:"Mean"`
+	P5     float64 `json:"P5"`
+	P50    float64 `json:"P50"`
+	P95    float64 `json:"P95"`
+	Target float64 `json:"Market Price"`
+	Prob   float64 `json:"Probability Above Price (%)"`
+}
+
+func (s simulation) String() string {
+	data, err := json.MarshalIndent(s, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling simulation data: ", err)
+	}
+	return string(data)
+}
+
+func meanStdDev(vals []float64) (float64, float64) {
+	if len(vals) == 0 {
+		return 0, 0
+	}
+	var sum, sq float64
+	for _, v := range vals {
+		sum += v
+	}
+	mean := sum / float64(len(vals))
+	for _, v := range vals {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(vals)))
+}
+
+func (v *valuator) MonteCarlo(ticker string, drMean float64, drStdDev float64, duration int,
+	runs int, seed int64, endYear ...int) (Simulation, error) {
+	price, err := v.marketPrice(ticker)
+	if err != nil {
+		log.Println("Simulating without a market price: ", err.Error())
+		price = 0
+	}
+	return v.monteCarlo(ticker, price, drMean, drStdDev, duration, runs, seed, endYear...)
+}
+
+func (v *valuator) monteCarlo(ticker string, price float64, drMean float64, drStdDev float64,
+	duration int, runs int, seed int64, endYear ...int) (Simulation, error) {
+
+	if len(endYear) > 1 {
+		return nil, errors.New("Specify only one end year for DCF calculation")
+	}
+	if runs <= 0 {
+		return nil, errors.New("Number of simulation runs should be positive")
+	}
+	vals, ok := v.Valuations[ticker]
+	if !ok {
+		return nil, errors.New("Valuator has not be told to collect data on " + ticker)
+	}
+
+	meas := vals.FiledData
+	if len(endYear) == 1 {
+		meas = createMeasuresList(vals.FiledData, endYear[0])
+	}
+	if len(meas) == 0 {
+		return nil, errors.New("No measures available for " + ticker)
+	}
+
+	// Growth distributions come from the YoY series
+	var fcfs, divs []float64
+	for _, m := range meas {
+		if y := m.Yoy(); !reflect.ValueOf(y).IsNil() {
+			fcfs = append(fcfs, y.CashFlowGrowth())
+			divs = append(divs, y.DividendGrowth())
+		}
+	}
+	if len(fcfs) == 0 {
+		return nil, errors.New("No YoY information found. Cannot simulate")
+	}
+	fcfMean, fcfStdDev := meanStdDev(fcfs)
+	divMean, divStdDev := meanStdDev(divs)
+	bv := meas[len(meas)-1].BookValue()
+
+	rng := rand.New(rand.NewSource(seed))
+	s := &simulation{
+		values: make([]float64, runs),
+		N:      runs,
+		Target: price,
+	}
+	var sum float64
+	var above int
+	for i := 0; i < runs; i++ {
+		fcf := fcfMean + rng.NormFloat64()*fcfStdDev
+		div := divMean + rng.NormFloat64()*divStdDev
+		dr := drMean + rng.NormFloat64()*drStdDev
+		if dr <= 0 {
+			dr = impliedDiscountBounds[0]
+		}
+		val, err := v.DiscountedCashFlow(ticker, dr, bv*(fcf/100), div, duration, endYear...)
+		if err != nil {
+			return nil, err
+		}
+		s.values[i] = val
+		sum += val
+		if price > 0 && val > price {
+			above++
+		}
+	}
+	sort.Float64s(s.values)
+
+	s.Avg = round(sum / float64(runs))
+	s.P5 = s.Percentile(5)
+	s.P50 = s.Percentile(50)
+	s.P95 = s.Percentile(95)
+	if price > 0 {
+		s.Prob = percentage(float64(above) / float64(runs))
+	}
+	return s, nil
+}
+
+func (s *simulation) Runs() int {
+	return s.N
+}
+
+func (s *simulation) Mean() float64 {
+	return s.Avg
+}
+
+func (s *simulation) Percentile(p float64) float64 {
+	if len(s.values) == 0 {
+		return 0
+	}
+	idx := int(math.Ceil(p/100*float64(len(s.values)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx >= len(s.values) {
+		idx = len(s.values) - 1
+	}
+	return s.values[idx]
+}
+
+func (s *simulation) Price() float64 {
+	return s.Target
+}
+
+func (s *simulation) ProbabilityAbovePrice() float64 {
+	return s.Prob
+}
@@ -0,0 +1,215 @@
+package valuator
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+	"time"
+)
+
+// Multiple is a type definition for the price multiples tracked over time
+type Multiple string
+
+// MultiplePE is the price over earnings
+const MultiplePE Multiple = "P/E"
+
+// MultiplePB is the price over book value
+const MultiplePB Multiple = "P/B"
+
+// MultipleEVToOI is the enterprise value over operating income
+const MultipleEVToOI Multiple = "EV/Operating Income"
+
+// Multiples in the order they are reported
+var multiples = []Multiple{MultiplePE, MultiplePB, MultipleEVToOI}
+
+var multipleFuncs = map[Multiple]func(PriceBasedMetrics) (float64, error){
+	MultiplePE: func(pm PriceBasedMetrics) (float64, error) {
+		if pe := pm.PriceOverEarnings(); pe > 0 {
+			return pe, nil
+		}
+		return 0, errors.New("P/E is not meaningful without positive earnings")
+	},
+	MultiplePB: func(pm PriceBasedMetrics) (float64, error) {
+		return pm.PriceToBook()
+	},
+	MultipleEVToOI: func(pm PriceBasedMetrics) (float64, error) {
+		return pm.EVToOperatingIncome()
+	},
+}
+
+// maxPriceLag is how far back from the filing date a close can be taken
+// when there is no close on the day, ex: weekends and holidays
+const maxPriceLag = 7 * 24 * time.Hour
+
+// MultiplesHistory provides an interface to the price based metrics of each
+// filing at the close on the filing date
+type MultiplesHistory interface {
+	// Dates of the filings that had a close, oldest first
+	Dates() []string
+	// Metrics are the price based metrics of each date
+	Metrics() []PriceBasedMetrics
+	// Range is the low and high of a multiple over the filings
+	Range(mu Multiple) (float64, float64, error)
+	// Current is the multiple at today's price
+	Current(mu Multiple) (float64, error)
+	// Percentile is the % of the filings at which the multiple was below
+	// the current multiple
+	Percentile(mu Multiple) (float64, error)
+	String() string
+}
+
+type multiplesHistory struct {
+	dates   []string
+	metrics []PriceBasedMetrics
+	current PriceBasedMetrics
+	Hist    map[string]map[Multiple]float64 `json:"Multiples"`
+	Pctl    map[Multiple]float64            `json:"Current Percentile (%)"`
+}
+
+func (h multiplesHistory) String() string {
+	data, err := json.MarshalIndent(h, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling multiples history data: ", err)
+	}
+	return string(data)
+}
+
+// closeOn gets the close on the date or the last close before it
+func closeOn(prices []PricePoint, date time.Time) (float64, error) {
+	i := sort.Search(len(prices), func(i int) bool {
+		return time.Time(prices[i].Date).After(date)
+	})
+	if i == 0 {
+		return 0, errors.New("No price on or before " + getDateString(date))
+	}
+	p := prices[i-1]
+	if date.Sub(time.Time(p.Date)) > maxPriceLag {
+		return 0, errors.New("No price close to " + getDateString(date))
+	}
+	return p.Close, nil
+}
+
+func (v *valuator) MultiplesHistory(ticker string, src PriceSource) (MultiplesHistory, error) {
+	vals, ok := v.Valuations[ticker]
+	if !ok {
+		return nil, errors.New("Valuator has not be told to collect data on " + ticker)
+	}
+	return v.multiplesHistory(ticker, src, vals.Pbm)
+}
+
+func (v *valuator) multiplesHistory(ticker string, src PriceSource, current PriceBasedMetrics) (MultiplesHistory, error) {
+	if src == nil {
+		return nil, errors.New("No price source to get the price history from")
+	}
+	vals, ok := v.Valuations[ticker]
+	if !ok {
+		return nil, errors.New("Valuator has not be told to collect data on " + ticker)
+	}
+	prices, err := src.DailyCloses(ticker)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &multiplesHistory{
+		current: current,
+		Hist:    make(map[string]map[Multiple]float64),
+		Pctl:    make(map[Multiple]float64),
+	}
+	for _, m := range vals.FiledData {
+		price, err := closeOn(prices, m.Filing().FiledOn())
+		if err != nil {
+			continue
+		}
+		price = v.sharePrice(ticker, price)
+		// Values and averages as known on the filing date, before any of
+		// the later amendments. The averages are only needed for the PEG
+		known, err := measuresAsOf(vals, m.Filing().FiledOn())
+		if err != nil {
+			continue
+		}
+		avg, _ := newAverages(known)
+		pm := newPriceBasedMetricsAt(known[len(known)-1], avg, price)
+		h.dates = append(h.dates, m.FiledOn())
+		h.metrics = append(h.metrics, pm)
+		h.Hist[m.FiledOn()] = make(map[Multiple]float64)
+		for _, mu := range multiples {
+			if val, err := multipleFuncs[mu](pm); err == nil {
+				h.Hist[m.FiledOn()][mu] = val
+			}
+		}
+	}
+	if len(h.metrics) == 0 {
+		return nil, errors.New("No prices on the filing dates of " + ticker)
+	}
+	for _, mu := range multiples {
+		if p, err := h.Percentile(mu); err == nil {
+			h.Pctl[mu] = p
+		}
+	}
+	return h, nil
+}
+
+// values gets the multiple on each filing it could be computed for
+func (h *multiplesHistory) values(mu Multiple) ([]float64, error) {
+	fn, ok := multipleFuncs[mu]
+	if !ok {
+		return nil, errors.New("Unknown multiple " + string(mu))
+	}
+	var ret []float64
+	for _, pm := range h.metrics {
+		if val, err := fn(pm); err == nil {
+			ret = append(ret, val)
+		}
+	}
+	if len(ret) == 0 {
+		return nil, errors.New("No history of " + string(mu))
+	}
+	return ret, nil
+}
+
+func (h *multiplesHistory) Dates() []string {
+	return h.dates
+}
+
+func (h *multiplesHistory) Metrics() []PriceBasedMetrics {
+	return h.metrics
+}
+
+func (h *multiplesHistory) Range(mu Multiple) (float64, float64, error) {
+	vals, err := h.values(mu)
+	if err != nil {
+		return 0, 0, err
+	}
+	sort.Float64s(vals)
+	return vals[0], vals[len(vals)-1], nil
+}
+
+func (h *multiplesHistory) Current(mu Multiple) (float64, error) {
+	fn, ok := multipleFuncs[mu]
+	if !ok {
+		return 0, errors.New("Unknown multiple " + string(mu))
+	}
+	if h.current == nil {
+		return 0, errors.New("No current price metrics")
+	}
+	return fn(h.current)
+}
+
+func (h *multiplesHistory) Percentile(mu Multiple) (float64, error) {
+	vals, err := h.values(mu)
+	if err != nil {
+		return 0, err
+	}
+	cur, err := h.Current(mu)
+	if err != nil {
+		return 0, err
+	}
+	var below int
+	for _, val := range vals {
+		if val < cur {
+			below++
+		}
+	}
+	return percentage(float64(below) / float64(len(vals))), nil
+}
@@ -0,0 +1,200 @@
+package valuator
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"sort"
+)
+
+// LiquidationHaircuts are the % of book value lost on liquidating each asset class
+type LiquidationHaircuts struct {
+	Cash          float64
+	Securities    float64
+	OtherCurrent  float64
+	Goodwill      float64
+	Intangibles   float64
+	OtherLongTerm float64
+}
+
+// DefaultLiquidationHaircuts returns haircuts along the lines of Graham
+func DefaultLiquidationHaircuts() LiquidationHaircuts {
+	return LiquidationHaircuts{
+		Cash:          0,
+		Securities:    10,
+		OtherCurrent:  40,
+		Goodwill:      100,
+		Intangibles:   100,
+		OtherLongTerm: 85,
+	}
+}
+
+// NetNet provides an interface to the net current asset value (Graham net-net)
+// and the liquidation value of a stock
+type NetNet interface {
+	Ticker() string
+	// NCAVPerShare is current assets less all liabilities per share
+	NCAVPerShare() float64
+	// LiquidationValue is the per share value of the assets after haircuts
+	// less all liabilities
+	LiquidationValue() float64
+	Price() float64
+	// Discount is the % that the price is below the NCAV per share
+	Discount() float64
+	String() string
+}
+
+type netNet struct {
+	Tick   string  `json:"Ticker"`
+	Ncav   float64 `json:"NCAV Per Share"`
+	Liq    float64 `json:"Liquidation Value Per Share"`
+	Target float64 `json:"Market Price"`
+	Disc   float64 `json:"Discount to NCAV (%)"`
+}
+
+func (n netNet) String() string {
+	data, err := json.MarshalIndent(n, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling net-net data: ", err)
+	}
+	return string(data)
+}
+
+// totalLiabilities falls back to current liabilities and long-term debt when
+// the filing does not have total liabilities. This leaves out any other
+// long-term liabilities
+func totalLiabilities(f Filing) (float64, error) {
+	if liab, err := f.Liabilities(); err == nil {
+		return liab, nil
+	}
+	cl, err := f.CurrentLiabilities()
+	if err != nil {
+		return 0, err
+	}
+	ld, _ := f.LongTermDebt()
+	return cl + ld, nil
+}
+
+// totalAssets falls back to total equity and total liabilities when the
+// filing does not have total assets
+func totalAssets(f Filing) (float64, error) {
+	if as, err := f.Assets(); err == nil {
+		return as, nil
+	}
+	eq, err := f.TotalEquity()
+	if err != nil {
+		return 0, err
+	}
+	liab, err := totalLiabilities(f)
+	if err != nil {
+		return 0, err
+	}
+	return eq + liab, nil
+}
+
+func newNetNet(f Filing, price float64, h LiquidationHaircuts) (*netNet, error) {
+	ca, err := f.CurrentAssets()
+	if err != nil {
+		return nil, err
+	}
+	sc, err := f.ShareCount()
+	if err != nil {
+		return nil, err
+	}
+	liab, err := totalLiabilities(f)
+	if err != nil {
+		return nil, err
+	}
+	cash, _ := f.Cash()
+	sec, _ := f.Securities()
+	gw, _ := f.Goodwill()
+	intan, _ := f.Intangibles()
+	// Other long-term assets are left out when total assets are not known
+	var other float64
+	if as, err := f.Assets(); err == nil {
+		other = as - ca - gw - intan
+	}
+
+	recovered := func(val float64, haircut float64) float64 {
+		return val * (1 - haircut/100)
+	}
+	liq := recovered(cash, h.Cash) +
+		recovered(sec, h.Securities) +
+		recovered(ca-cash-sec, h.OtherCurrent) +
+		recovered(gw, h.Goodwill) +
+		recovered(intan, h.Intangibles) +
+		recovered(other, h.OtherLongTerm)
+
+	n := &netNet{
+		Tick:   f.Ticker(),
+		Ncav:   round((ca - liab) / sc),
+		Liq:    round((liq - liab) / sc),
+		Target: price,
+	}
+	if n.Ncav > 0 && price > 0 {
+		n.Disc = percentage((n.Ncav - price) / n.Ncav)
+	}
+	return n, nil
+}
+
+func (v *valuator) NetCurrentAssetValue(ticker string, haircuts LiquidationHaircuts) (NetNet, error) {
+	vals, ok := v.Valuations[ticker]
+	if !ok {
+		return nil, errors.New("Valuator has not be told to collect data on " + ticker)
+	}
+	if err := priceCurrency(ticker, vals); err != nil {
+		return nil, err
+	}
+	var price float64
+	if vals.Pbm != nil {
+		price = vals.Pbm.Price()
+	}
+	n, err := newNetNet(vals.FiledData[len(vals.FiledData)-1].Filing(), price, haircuts)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+func (v *valuator) NetNetScreen(haircuts LiquidationHaircuts) ([]NetNet, error) {
+	var ret []NetNet
+	for _, ticker := range v.store.tickers() {
+		if err := v.Collect(ticker); err != nil {
+			log.Println("Skipping " + ticker + " in net-net screen: " + err.Error())
+			continue
+		}
+		n, err := v.NetCurrentAssetValue(ticker, haircuts)
+		if err != nil {
+			log.Println("Skipping " + ticker + " in net-net screen: " + err.Error())
+			continue
+		}
+		if n.Price() > 0 && n.Price() < n.NCAVPerShare() {
+			ret = append(ret, n)
+		}
+	}
+	// Deepest discount first
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Discount() > ret[j].Discount()
+	})
+	return ret, nil
+}
+
+func (n *netNet) Ticker() string {
+	return n.Tick
+}
+
+func (n *netNet) NCAVPerShare() float64 {
+	return n.Ncav
+}
+
+func (n *netNet) LiquidationValue() float64 {
+	return n.Liq
+}
+
+func (n *netNet) Price() float64 {
+	return n.Target
+}
+
+func (n *netNet) Discount() float64 {
+	return n.Disc
+}
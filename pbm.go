@@ -1,51 +1,172 @@
 package valuator
 
+import (
+	"errors"
+	"log"
+	"math"
+	"reflect"
+)
+
 // PriceBasedMetrics provides an interface for price based stock metrics
 type PriceBasedMetrics interface {
+	// Price is of an ordinary share, the quote over the ADR ratio for an ADR
+	Price() float64
+	// Currency is the ISO 4217 code of the price and the market values
+	Currency() string
 	EnterpriseValue() float64
 	MarketCapitalization() float64
+	// The P/x values below are 0 when the denominator is not positive
 	PriceOverEarnings() float64
 	PriceOverCashFlow() float64
 	PriceOverRevenue() float64
+	// The ratios below return an error when there is no market price, the
+	// price and filings are in different currencies or the denominator is
+	// missing or not positive
+	PriceToBook() (float64, error)
+	PriceToTangibleBook() (float64, error)
+	EVToRevenue() (float64, error)
+	EVToOperatingIncome() (float64, error)
+	// EarningsYield is the net income over the market capitalization in %
+	EarningsYield() (float64, error)
+	// FCFYield is the operating cash flow less capex over the market
+	// capitalization in %
+	FCFYield() (float64, error)
+	// DividendYield is the dividend per share over the price in %
+	DividendYield() (float64, error)
+	// PEG is the P/E over the average earnings growth in %
+	PEG() (float64, error)
+	// BuybackYield is the % of shares retired, net of issuance, in the last
+	// year. Negative when the shares were diluted
+	BuybackYield() float64
+	// ShareholderYield is the dividend and buyback yield together
+	ShareholderYield() (float64, error)
+	AltmanZ() AltmanZ
 }
 
+// Names of the price ratios for the errors
+const (
+	ratioPriceToBook         = "P/B"
+	ratioPriceToTangibleBook = "P/TBV"
+	ratioEVToRevenue         = "EV/Revenue"
+	ratioEVToOpsIncome       = "EV/Operating Income"
+	ratioEarningsYield       = "Earnings Yield"
+	ratioFCFYield            = "FCF Yield"
+	ratioDividendYield       = "Dividend Yield"
+	ratioPEG                 = "PEG"
+)
+
 type pbm struct {
-	measures  Measures
-	Price     float64 `json:"Market Price"`
-	Ev        float64 `json:"Enterprise Value"`
-	MarketCap float64 `json:"Market Capitalization"`
-	PoverE    float64 `json:"Price To Earnings"`
-	PoverCF   float64 `json:"Price To CashFlow"`
-	PoverRev  float64 `json:"Price To Revenue"`
+	measures    Measures
+	MarketPrice float64  `json:"Market Price"`
+	Cur         string   `json:"Currency"`
+	Ev          float64  `json:"Enterprise Value"`
+	MarketCap   float64  `json:"Market Capitalization"`
+	PoverE      float64  `json:"Price To Earnings"`
+	PoverCF     float64  `json:"Price To CashFlow"`
+	PoverRev    float64  `json:"Price To Revenue"`
+	PtoB        *float64 `json:"Price To Book,omitempty"`
+	PtoTbv      *float64 `json:"Price To Tangible Book,omitempty"`
+	EvToRev     *float64 `json:"EV To Revenue,omitempty"`
+	EvToOi      *float64 `json:"EV To Operating Income,omitempty"`
+	EarnYield   *float64 `json:"Earnings Yield (%),omitempty"`
+	FcfYield    *float64 `json:"FCF Yield (%),omitempty"`
+	DivYield    *float64 `json:"Dividend Yield (%),omitempty"`
+	Peg         *float64 `json:"PEG,omitempty"`
+	BbYield     float64  `json:"Buyback Yield (%)"`
+	ShYield     *float64 `json:"Shareholder Yield (%),omitempty"`
+	Az          *altmanZ `json:"Altman Z"`
+	errs        map[string]error
+}
+
+// newPriceBasedMetricsAt is for a price in USD as quoted on US exchanges. The
+// price of an ADR should be over its ADR ratio
+func newPriceBasedMetricsAt(m Measures, avg Average, price float64) PriceBasedMetrics {
+	return newPriceBasedMetricsIn(m, avg, price, defaultCurrency)
 }
 
-func newPriceBasedMetrics(m Measures) PriceBasedMetrics {
+func newPriceBasedMetricsIn(m Measures, avg Average, price float64, currency string) PriceBasedMetrics {
 	pm := &pbm{
-		measures: m,
-		Price:    priceFetcher(m.Filing().Ticker()),
+		measures:    m,
+		MarketPrice: price,
+		Cur:         currency,
+		errs:        make(map[string]error),
 	}
 	// Enterprise Value
 	cash, _ := m.Filing().Cash()
 	ld, _ := m.Filing().LongTermDebt()
 	sd, _ := m.Filing().ShortTermDebt()
 	sc, _ := m.Filing().ShareCount()
-	pm.MarketCap = round(sc * pm.Price)
+	pm.MarketCap = round(sc * pm.MarketPrice)
 	pm.Ev = round(pm.MarketCap + ld + sd - cash)
 
-	// Price over Earnings
-	if ni, err := m.Filing().NetIncome(); err == nil {
+	// Price over Earnings. Losses have no meaningful P/E
+	if ni, err := m.Filing().NetIncome(); err == nil && ni > 0 {
 		pm.PoverE = round(pm.MarketCap / ni)
 	}
 
 	// Price over Cash flow
-	if cf, err := m.Filing().OperatingCashFlow(); err == nil {
+	if cf, err := m.Filing().OperatingCashFlow(); err == nil && cf > 0 {
 		pm.PoverCF = round(pm.MarketCap / cf)
 	}
 
-	if rev, err := m.Filing().Revenue(); err == nil {
+	if rev, err := m.Filing().Revenue(); err == nil && rev > 0 {
 		pm.PoverRev = round(pm.MarketCap / rev)
 	}
 
+	// Market values over filing values in another currency are meaningless
+	if pm.Cur != m.Currency() {
+		log.Println("Price in " + pm.Cur + " and filings in " + currencyName(m.Currency()) + ". Report in one currency with ReportIn")
+		pm.Ev, pm.PoverE, pm.PoverCF, pm.PoverRev = 0, 0, 0, 0
+	}
+
+	f := m.Filing()
+	eq, err := f.TotalEquity()
+	pm.PtoB = pm.ratio(ratioPriceToBook, pm.MarketCap, eq, err)
+	teq, err := tangibleEquity(f)
+	pm.PtoTbv = pm.ratio(ratioPriceToTangibleBook, pm.MarketCap, teq, err)
+	rev, err := f.Revenue()
+	pm.EvToRev = pm.ratio(ratioEVToRevenue, pm.Ev, rev, err)
+	oi, err := f.OperatingIncome()
+	pm.EvToOi = pm.ratio(ratioEVToOpsIncome, pm.Ev, oi, err)
+
+	// Yields can be negative as only the market capitalization is divided by
+	ni, niErr := f.NetIncome()
+	pm.EarnYield = pm.ratio(ratioEarningsYield, ni*100, pm.MarketCap, niErr)
+	ocf, err := f.OperatingCashFlow()
+	capex, _ := f.CapitalExpenditure()
+	pm.FcfYield = pm.ratio(ratioFCFYield, (ocf-math.Abs(capex))*100, pm.MarketCap, err)
+	pm.DivYield = pm.ratio(ratioDividendYield, m.DividendPerShare()*100, pm.MarketPrice, nil)
+
+	// PEG needs positive earnings and growth
+	if pe := pm.ratio(ratioPEG, pm.MarketCap, ni, niErr); pe != nil {
+		switch {
+		case avg == nil:
+			pm.errs[ratioPEG] = errors.New("No average earnings growth for " + ratioPEG)
+		case avg.AvgEarningsGrowth() <= 0:
+			pm.errs[ratioPEG] = errors.New("Average earnings growth for " + ratioPEG + " is not positive")
+		default:
+			peg := round(*pe / avg.AvgEarningsGrowth())
+			pm.Peg = &peg
+		}
+	}
+
+	// Shareholder yield. Buybacks are valued at the current price, which
+	// cancels out to the share count change over the current share count
+	if y := m.Yoy(); !reflect.ValueOf(y).IsNil() && sc > 0 {
+		if past, err := y.Past().Filing().ShareCount(); err == nil {
+			pm.BbYield = round((past - sc) / sc * 100)
+		}
+	}
+	if pm.DivYield != nil {
+		sh := round(*pm.DivYield + pm.BbYield)
+		pm.ShYield = &sh
+	}
+
+	// The market value of equity needs a price
+	if pm.MarketPrice > 0 && pm.Cur == m.Currency() {
+		pm.Az = newAltmanZ(m, pm.MarketCap)
+	}
+
 	return pm
 }
 
@@ -53,6 +174,45 @@ func (p *pbm) collect() {
 
 }
 
+// ratio is num/den unless there is no market price, the currencies differ or
+// the denominator is missing or not positive, in which case the error is
+// kept for the name
+func (p *pbm) ratio(name string, num float64, den float64, err error) *float64 {
+	switch {
+	case p.MarketPrice <= 0:
+		p.errs[name] = errors.New("No market price for " + name)
+	case p.Cur != p.measures.Currency():
+		p.errs[name] = errors.New("Price in " + p.Cur + " and filings in " + currencyName(p.measures.Currency()) + " for " + name)
+	case err != nil:
+		p.errs[name] = errors.New("No denominator for " + name + ": " + err.Error())
+	case den <= 0:
+		p.errs[name] = errors.New("Denominator of " + name + " is not positive")
+	default:
+		val := round(num / den)
+		return &val
+	}
+	return nil
+}
+
+// metric gets a ratio or the error it could not be computed with
+func (p *pbm) metric(name string, val *float64) (float64, error) {
+	if val == nil {
+		if err, ok := p.errs[name]; ok {
+			return 0, err
+		}
+		return 0, errors.New("No value for " + name)
+	}
+	return *val, nil
+}
+
+func (p *pbm) Price() float64 {
+	return p.MarketPrice
+}
+
+func (p *pbm) Currency() string {
+	return p.Cur
+}
+
 func (p *pbm) EnterpriseValue() float64 {
 	return p.Ev
 }
@@ -72,3 +232,52 @@ func (p *pbm) MarketCapitalization() float64 {
 func (p *pbm) PriceOverRevenue() float64 {
 	return p.PoverRev
 }
+
+// AltmanZ uses the market capitalization as the market value of equity
+func (p *pbm) AltmanZ() AltmanZ {
+	if p.Az == nil {
+		return nil
+	}
+	return p.Az
+}
+
+func (p *pbm) PriceToBook() (float64, error) {
+	return p.metric(ratioPriceToBook, p.PtoB)
+}
+
+func (p *pbm) PriceToTangibleBook() (float64, error) {
+	return p.metric(ratioPriceToTangibleBook, p.PtoTbv)
+}
+
+func (p *pbm) EVToRevenue() (float64, error) {
+	return p.metric(ratioEVToRevenue, p.EvToRev)
+}
+
+func (p *pbm) EVToOperatingIncome() (float64, error) {
+	return p.metric(ratioEVToOpsIncome, p.EvToOi)
+}
+
+func (p *pbm) EarningsYield() (float64, error) {
+	return p.metric(ratioEarningsYield, p.EarnYield)
+}
+
+func (p *pbm) FCFYield() (float64, error) {
+	return p.metric(ratioFCFYield, p.FcfYield)
+}
+
+func (p *pbm) DividendYield() (float64, error) {
+	return p.metric(ratioDividendYield, p.DivYield)
+}
+
+func (p *pbm) PEG() (float64, error) {
+	return p.metric(ratioPEG, p.Peg)
+}
+
+func (p *pbm) BuybackYield() float64 {
+	return p.BbYield
+}
+
+// ShareholderYield has the error of the dividend yield if there is no price
+func (p *pbm) ShareholderYield() (float64, error) {
+	return p.metric(ratioDividendYield, p.ShYield)
+}
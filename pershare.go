@@ -0,0 +1,257 @@
+package valuator
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+)
+
+// ShareBasis is a type definition for the share count used for per share values
+type ShareBasis string
+
+// BasicShares is the share count at the end of the fiscal year
+const BasicShares ShareBasis = "Basic"
+
+// WeightedShares is the weighted average share count over the fiscal year
+const WeightedShares ShareBasis = "Weighted"
+
+// shareBases is the order in which the per share values are reported
+var shareBases = []ShareBasis{BasicShares, WeightedShares}
+
+// PerShare provides an interface to the company values over a share count
+type PerShare interface {
+	Basis() ShareBasis
+	Shares() float64
+	Earnings() float64
+	Revenue() float64
+	FreeCashFlow() float64
+	BookValue() float64
+	String() string
+}
+
+type perShare struct {
+	basis ShareBasis
+	Sc    float64 `json:"Shares"`
+	Eps   float64 `json:"Earnings"`
+	Rev   float64 `json:"Revenue"`
+	Fcf   float64 `json:"Free Cash Flow"`
+	Bv    float64 `json:"Book Value"`
+}
+
+// perShares are the per share values on each basis
+type perShares map[ShareBasis]*perShare
+
+func (p perShare) String() string {
+	data, err := json.MarshalIndent(p, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling per share data: ", err)
+	}
+	return string(data)
+}
+
+// PerShareGrowth provides an interface to the growth (%) of each per share
+// value in Yoy and Average
+type PerShareGrowth interface {
+	Basis() ShareBasis
+	Shares() float64
+	Earnings() float64
+	Revenue() float64
+	FreeCashFlow() float64
+	BookValue() float64
+	String() string
+}
+
+type perShareGrowth struct {
+	basis ShareBasis
+	Sc    float64 `json:"Shares (%)"`
+	Eps   float64 `json:"Earnings (%)"`
+	Rev   float64 `json:"Revenue (%)"`
+	Fcf   float64 `json:"Free Cash Flow (%)"`
+	Bv    float64 `json:"Book Value (%)"`
+}
+
+// perShareGrowths are the per share growths on each basis
+type perShareGrowths map[ShareBasis]*perShareGrowth
+
+func (g perShareGrowth) String() string {
+	data, err := json.MarshalIndent(g, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling per share growth data: ", err)
+	}
+	return string(data)
+}
+
+// shares gets the share count of a filing on the basis
+func shares(f Filing, basis ShareBasis) (float64, error) {
+	switch basis {
+	case BasicShares:
+		return f.ShareCount()
+	case WeightedShares:
+		return f.WAShares()
+	}
+	return 0, errors.New("Unknown share basis " + string(basis))
+}
+
+func newPerShare(m Measures, basis ShareBasis) *perShare {
+	f := m.Filing()
+	sc, err := shares(f, basis)
+	if err != nil || sc == 0 {
+		return nil
+	}
+	ni, _ := f.NetIncome()
+	rev, _ := f.Revenue()
+	eq, _ := f.TotalEquity()
+	return &perShare{
+		basis: basis,
+		Sc:    sc,
+		Eps:   round(ni / sc),
+		Rev:   round(rev / sc),
+		Fcf:   round(m.FreeCashFlow() / sc),
+		Bv:    round(eq / sc),
+	}
+}
+
+// newPerShareGrowth is the growth (%) of each per share value. The values are
+// taken from the filings so the growth is not affected by rounding
+func newPerShareGrowth(past Measures, current Measures, basis ShareBasis) *perShareGrowth {
+	p := newPerShare(past, basis)
+	c := newPerShare(current, basis)
+	if p == nil || c == nil {
+		return nil
+	}
+	pni, _ := past.Filing().NetIncome()
+	cni, _ := current.Filing().NetIncome()
+	prev, _ := past.Filing().Revenue()
+	crev, _ := current.Filing().Revenue()
+	peq, _ := past.Filing().TotalEquity()
+	ceq, _ := current.Filing().TotalEquity()
+	return &perShareGrowth{
+		basis: basis,
+		Sc:    yoyCalc(p.Sc, c.Sc, true),
+		Eps:   yoyCalc(pni/p.Sc, cni/c.Sc, true),
+		Rev:   yoyCalc(prev/p.Sc, crev/c.Sc, true),
+		Fcf:   yoyCalc(past.FreeCashFlow()/p.Sc, current.FreeCashFlow()/c.Sc, true),
+		Bv:    yoyCalc(peq/p.Sc, ceq/c.Sc, true),
+	}
+}
+
+// newAvgPerShareGrowth averages the per share growth over the years
+func newAvgPerShareGrowth(y []Yoy, basis ShareBasis) *perShareGrowth {
+	avg := &perShareGrowth{basis: basis}
+	var n float64
+	for _, val := range y {
+		g := val.PerShareGrowth(basis)
+		if g == nil {
+			continue
+		}
+		avg.Sc += g.Shares()
+		avg.Eps += g.Earnings()
+		avg.Rev += g.Revenue()
+		avg.Fcf += g.FreeCashFlow()
+		avg.Bv += g.BookValue()
+		n++
+	}
+	if n == 0 {
+		return nil
+	}
+	avg.Sc = avgCalc(avg.Sc, n)
+	avg.Eps = avgCalc(avg.Eps, n)
+	avg.Rev = avgCalc(avg.Rev, n)
+	avg.Fcf = avgCalc(avg.Fcf, n)
+	avg.Bv = avgCalc(avg.Bv, n)
+	return avg
+}
+
+// lastShares gets the shares on the basis as of the end year
+func (v *valuator) lastShares(ticker string, basis ShareBasis, endYear ...int) (float64, error) {
+	if len(endYear) > 1 {
+		return 0, errors.New("Specify only one end year for DCF calculation")
+	}
+	vals, ok := v.Valuations[ticker]
+	if !ok {
+		return 0, errors.New("Valuator has not be told to collect data on " + ticker)
+	}
+	meas := vals.FiledData
+	if len(endYear) == 1 {
+		meas = createMeasuresList(vals.FiledData, endYear[0])
+	}
+	if len(meas) == 0 {
+		return 0, errors.New("No measures available for " + ticker)
+	}
+	sc, err := shares(meas[len(meas)-1].Filing(), basis)
+	if err != nil {
+		return 0, err
+	}
+	if sc <= 0 {
+		return 0, errors.New("No " + string(basis) + " share count for " + ticker)
+	}
+	return sc, nil
+}
+
+// CompanyValue is the whole company value of a per share value from the DCF
+// methods. The DCF methods only value a basic share so the basic shares are
+// used
+func (v *valuator) CompanyValue(ticker string, perShare float64, endYear ...int) (float64, error) {
+	sc, err := v.lastShares(ticker, BasicShares, endYear...)
+	if err != nil {
+		return 0, err
+	}
+	return round(perShare * sc), nil
+}
+
+// ShareValue is the per share value of a whole company value on the basis
+func (v *valuator) ShareValue(ticker string, companyValue float64, basis ShareBasis, endYear ...int) (float64, error) {
+	sc, err := v.lastShares(ticker, basis, endYear...)
+	if err != nil {
+		return 0, err
+	}
+	return round(companyValue / sc), nil
+}
+
+func (p *perShare) Basis() ShareBasis {
+	return p.basis
+}
+
+func (p *perShare) Shares() float64 {
+	return p.Sc
+}
+
+func (p *perShare) Earnings() float64 {
+	return p.Eps
+}
+
+func (p *perShare) Revenue() float64 {
+	return p.Rev
+}
+
+func (p *perShare) FreeCashFlow() float64 {
+	return p.Fcf
+}
+
+func (p *perShare) BookValue() float64 {
+	return p.Bv
+}
+
+func (g *perShareGrowth) Basis() ShareBasis {
+	return g.basis
+}
+
+func (g *perShareGrowth) Shares() float64 {
+	return g.Sc
+}
+
+func (g *perShareGrowth) Earnings() float64 {
+	return g.Eps
+}
+
+func (g *perShareGrowth) Revenue() float64 {
+	return g.Rev
+}
+
+func (g *perShareGrowth) FreeCashFlow() float64 {
+	return g.Fcf
+}
+
+func (g *perShareGrowth) BookValue() float64 {
+	return g.Bv
+}
@@ -0,0 +1,137 @@
+package valuator
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+)
+
+// Number of trading days used to annualize daily figures
+const tradingDays = 252
+
+// PriceHistoryMetrics provides an interface for metrics based on the price
+// history of a stock against a benchmark
+type PriceHistoryMetrics interface {
+	Benchmark() string
+	Beta() float64
+	// Volatility is the annualized standard deviation of daily returns in %
+	Volatility() float64
+	// MaxDrawdown is the largest % fall from a peak close
+	MaxDrawdown() float64
+	// Correlation of the daily returns with the benchmark
+	Correlation() float64
+	String() string
+}
+
+type phm struct {
+	Bench string  `json:"Benchmark"`
+	B     float64 `json:"Beta"`
+	Vol   float64 `json:"Annualized Volatility (%)"`
+	Mdd   float64 `json:"Max Drawdown (%)"`
+	Corr  float64 `json:"Correlation"`
+}
+
+func (p phm) String() string {
+	data, err := json.MarshalIndent(p, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling price history data: ", err)
+	}
+	return string(data)
+}
+
+func newPriceHistoryMetrics(stock []PricePoint, bench []PricePoint, benchmark string) (PriceHistoryMetrics, error) {
+	rs, rb := dailyReturns(stock, bench)
+	if len(rs) < 2 {
+		return nil, errors.New("Not enough overlapping prices against " + benchmark)
+	}
+	ms, ss := meanStdDev(rs)
+	mb, sb := meanStdDev(rb)
+	if sb == 0 || ss == 0 {
+		return nil, errors.New("Prices do not vary. Cannot compute price history metrics")
+	}
+	var cov float64
+	for i := range rs {
+		cov += (rs[i] - ms) * (rb[i] - mb)
+	}
+	cov = cov / float64(len(rs))
+
+	return &phm{
+		Bench: benchmark,
+		B:     round(cov / (sb * sb)),
+		Vol:   round(ss * math.Sqrt(tradingDays) * 100),
+		Mdd:   round(maxDrawdown(stock) * 100),
+		Corr:  round(cov / (ss * sb)),
+	}, nil
+}
+
+func (v *valuator) PriceHistoryMetrics(ticker string, benchmark string, src PriceSource) (PriceHistoryMetrics, error) {
+	if src == nil {
+		return nil, errors.New("No price source to get the price history from")
+	}
+	stock, err := src.DailyCloses(ticker)
+	if err != nil {
+		return nil, err
+	}
+	bench, err := src.DailyCloses(benchmark)
+	if err != nil {
+		return nil, err
+	}
+	return newPriceHistoryMetrics(stock, bench, benchmark)
+}
+
+// dailyReturns gets the returns of the stock and the benchmark on the days
+// that both have a close
+func dailyReturns(stock []PricePoint, bench []PricePoint) ([]float64, []float64) {
+	closes := make(map[string]float64)
+	for _, p := range bench {
+		closes[p.Date.String()] = p.Close
+	}
+	var rs, rb []float64
+	var prevS, prevB float64
+	for _, p := range stock {
+		b, ok := closes[p.Date.String()]
+		if !ok {
+			continue
+		}
+		if prevS > 0 && prevB > 0 {
+			rs = append(rs, (p.Close-prevS)/prevS)
+			rb = append(rb, (b-prevB)/prevB)
+		}
+		prevS, prevB = p.Close, b
+	}
+	return rs, rb
+}
+
+func maxDrawdown(prices []PricePoint) float64 {
+	var peak, mdd float64
+	for _, p := range prices {
+		if p.Close > peak {
+			peak = p.Close
+		}
+		if peak > 0 && (peak-p.Close)/peak > mdd {
+			mdd = (peak - p.Close) / peak
+		}
+	}
+	return mdd
+}
+
+func (p *phm) Benchmark() string {
+	return p.Bench
+}
+
+func (p *phm) Beta() float64 {
+	return p.B
+}
+
+func (p *phm) Volatility() float64 {
+	return p.Vol
+}
+
+func (p *phm) MaxDrawdown() float64 {
+	return p.Mdd
+}
+
+func (p *phm) Correlation() float64 {
+	return p.Corr
+}
@@ -0,0 +1,161 @@
+package valuator
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"math"
+)
+
+// Verdict is a type definition for the outcome of comparing value to price
+type Verdict string
+
+// VerdictBuy is when the margin of safety is at or above the buy margin
+const VerdictBuy Verdict = "buy"
+
+// VerdictHold is when the margin of safety is between the avoid and buy margins
+const VerdictHold Verdict = "hold"
+
+// VerdictAvoid is when the margin of safety is below the avoid margin
+const VerdictAvoid Verdict = "avoid"
+
+// PriceRangeOptions are the assumptions and thresholds used by PriceRange
+type PriceRangeOptions struct {
+	// DiscountRate in % for all the valuation models
+	DiscountRate float64
+	// Duration over which to discount the CF
+	Duration int
+	// LowTrend and HighTrend are the % of trend for the low and high values
+	LowTrend  float64
+	HighTrend float64
+	// BuyMargin is the margin of safety (%) at or above which to buy
+	BuyMargin float64
+	// AvoidMargin is the margin of safety (%) below which to avoid
+	AvoidMargin float64
+}
+
+// DefaultPriceRangeOptions returns the options used when none are specified
+func DefaultPriceRangeOptions() PriceRangeOptions {
+	return PriceRangeOptions{
+		DiscountRate: 3,
+		Duration:     10,
+		LowTrend:     50,
+		HighTrend:    150,
+		BuyMargin:    25,
+		AvoidMargin:  0,
+	}
+}
+
+// IntrinsicRange provides an interface to the intrinsic value range of a stock
+type IntrinsicRange interface {
+	Low() float64
+	Mid() float64
+	High() float64
+	Price() float64
+	// MarginOfSafety is the % discount of the price to the mid value
+	MarginOfSafety() float64
+	Verdict() Verdict
+	String() string
+}
+
+type intrinsicRange struct {
+	Lo     float64 `json:"Low"`
+	Md     float64 `json:"Mid"`
+	Hi     float64 `json:"High"`
+	Target float64 `json:"Market Price"`
+	MoS    float64 `json:"Margin of Safety (%)"`
+	Verd   Verdict `json:"Verdict"`
+}
+
+func (r intrinsicRange) String() string {
+	data, err := json.MarshalIndent(r, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling price range data: ", err)
+	}
+	return string(data)
+}
+
+func (v *valuator) PriceRange(ticker string, opts PriceRangeOptions) (IntrinsicRange, error) {
+	price, err := v.marketPrice(ticker)
+	if err != nil {
+		return nil, err
+	}
+	return v.priceRange(ticker, price, opts)
+}
+
+func (v *valuator) priceRange(ticker string, price float64, opts PriceRangeOptions) (IntrinsicRange, error) {
+	if opts.DiscountRate <= 0 || opts.Duration <= 0 {
+		return nil, errors.New("Price range needs a positive discount rate and duration")
+	}
+	if opts.LowTrend > 100 || opts.HighTrend < 100 {
+		return nil, errors.New("Price range needs a low trend <= 100 and a high trend >= 100")
+	}
+	if opts.AvoidMargin > opts.BuyMargin {
+		return nil, errors.New("Avoid margin should not be above the buy margin")
+	}
+
+	scenarios := []Scenario{
+		{Name: "low", Trend: opts.LowTrend, DiscountRate: opts.DiscountRate, Duration: opts.Duration},
+		{Name: "mid", Trend: 100, DiscountRate: opts.DiscountRate, Duration: opts.Duration},
+		{Name: "high", Trend: opts.HighTrend, DiscountRate: opts.DiscountRate, Duration: opts.Duration},
+	}
+	sm, err := v.ScenarioValuations(ticker, scenarios)
+	if err != nil {
+		return nil, err
+	}
+
+	// Low and high span every model while mid is the average of the models
+	ret := &intrinsicRange{
+		Lo:     math.Inf(1),
+		Hi:     math.Inf(-1),
+		Target: price,
+	}
+	for _, model := range sm.Models() {
+		for _, s := range sm.Scenarios() {
+			val, _ := sm.Value(s, model)
+			ret.Lo = math.Min(ret.Lo, val)
+			ret.Hi = math.Max(ret.Hi, val)
+		}
+		mid, _ := sm.Value("mid", model)
+		ret.Md += mid
+	}
+	ret.Md = round(ret.Md / float64(len(sm.Models())))
+
+	if ret.Md <= 0 {
+		return nil, errors.New("No positive intrinsic value for " + ticker)
+	}
+	ret.MoS = percentage((ret.Md - price) / ret.Md)
+	switch {
+	case ret.MoS >= opts.BuyMargin:
+		ret.Verd = VerdictBuy
+	case ret.MoS < opts.AvoidMargin:
+		ret.Verd = VerdictAvoid
+	default:
+		ret.Verd = VerdictHold
+	}
+	return ret, nil
+}
+
+func (r *intrinsicRange) Low() float64 {
+	return r.Lo
+}
+
+func (r *intrinsicRange) Mid() float64 {
+	return r.Md
+}
+
+func (r *intrinsicRange) High() float64 {
+	return r.Hi
+}
+
+func (r *intrinsicRange) Price() float64 {
+	return r.Target
+}
+
+func (r *intrinsicRange) MarginOfSafety() float64 {
+	return r.MoS
+}
+
+func (r *intrinsicRange) Verdict() Verdict {
+	return r.Verd
+}
@@ -0,0 +1,97 @@
+package valuator
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// PriceSourceType is a type definition of the different price history sources
+type PriceSourceType string
+
+// CSVPriceSourceType is a folder of <ticker>.csv files with daily closes
+const CSVPriceSourceType PriceSourceType = "csv"
+
+// PricePoint is the closing price of a ticker on a day
+type PricePoint struct {
+	Date  Timestamp `json:"Date"`
+	Close float64   `json:"Close"`
+}
+
+// PriceSource is an interface to the source of historical prices
+type PriceSource interface {
+	// Name of the price source
+	Name() string
+	// DailyCloses returns the daily closes of a ticker in date order
+	DailyCloses(ticker string) ([]PricePoint, error)
+}
+
+// NewPriceSource creates a source of historical prices
+func NewPriceSource(url interface{}, ty PriceSourceType) (PriceSource, error) {
+	switch ty {
+	case CSVPriceSourceType:
+		if path, ok := url.(string); ok {
+			if _, err := os.Stat(path); err != nil {
+				return nil, err
+			}
+			return &csvPriceSource{path: path}, nil
+		}
+		return nil, errors.New("CSV price source needs a folder path")
+	default:
+	}
+	log.Println("Unknown price source type ", ty)
+	return nil, errors.New("Unsupported price source " + string(ty))
+}
+
+// csvPriceSource reads daily closes from CSV files in a folder. The name of
+// the file is the ticker and every row has a date (YYYY-MM-DD) and a close.
+// A header row is skipped.
+type csvPriceSource struct {
+	path string
+}
+
+func (c *csvPriceSource) Name() string {
+	return "CSV Price Source"
+}
+
+func (c *csvPriceSource) DailyCloses(ticker string) ([]PricePoint, error) {
+	fd, err := os.Open(c.path + ticker + ".csv")
+	if err != nil {
+		return nil, errors.New("No price history available for " + ticker)
+	}
+	defer fd.Close()
+	return readPriceCSV(fd)
+}
+
+func readPriceCSV(r io.Reader) ([]PricePoint, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	var ret []PricePoint
+	for i, rec := range records {
+		if len(rec) < 2 {
+			return nil, errors.New("Price history needs a date and a close on every row")
+		}
+		cl, err := strconv.ParseFloat(strings.TrimSpace(rec[1]), 64)
+		if err != nil {
+			if i == 0 {
+				continue
+			}
+			return nil, err
+		}
+		ret = append(ret, PricePoint{
+			Date:  getDate(strings.TrimSpace(rec[0])),
+			Close: cl,
+		})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Date.String() < ret[j].Date.String()
+	})
+	return ret, nil
+}
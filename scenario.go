@@ -0,0 +1,255 @@
+package valuator
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"log"
+	"math"
+)
+
+// ValuationModel is a type definition for the valuation methods run per scenario
+type ValuationModel string
+
+// ModelDCFTrend is the DiscountedCashFlowTrend valuation
+const ModelDCFTrend ValuationModel = "DCF (BV & DIV)"
+
+// ModelFCFTrend is the DiscountedFCFTrend valuation
+const ModelFCFTrend ValuationModel = "DCF (FCF)"
+
+// ModelOwnerEarnings is the DiscountedOwnerEarnings valuation
+const ModelOwnerEarnings ValuationModel = "DCF (Owner Earnings)"
+
+// ModelEPV is the EarningsPowerValue valuation
+const ModelEPV ValuationModel = "EPV"
+
+// Defaults for the EPV when a scenario does not set them
+const (
+	defaultTaxRate     = 21
+	defaultMaintenance = 100
+)
+
+// Scenario is a named set of assumptions for the valuation methods
+type Scenario struct {
+	Name string `json:"Name"`
+	// Trend is the % of the averages to factor in, as in DiscountedCashFlowTrend
+	Trend float64 `json:"Trend"`
+	// DiscountRate is the discount rate in %
+	DiscountRate float64 `json:"Discount Rate"`
+	// Duration is the horizon over which to discount the CF
+	Duration int `json:"Duration"`
+	// TerminalGrowth is the perpetual growth (%) of the cash out after the
+	// horizon. No terminal value is added when it is not set
+	TerminalGrowth *float64 `json:"Terminal Growth,omitempty"`
+	// Weight is the probability of the scenario used for the weighted value
+	Weight float64 `json:"Weight,omitempty"`
+	// TaxRate is the tax rate (%) on the operating income for the EPV. The
+	// US federal rate of 21% is used when it is not set
+	TaxRate *float64 `json:"Tax Rate,omitempty"`
+	// Maintenance is the % of the capex that maintains the business for the
+	// EPV. All of the capex is maintenance when it is not set
+	Maintenance *float64 `json:"Maintenance Capex,omitempty"`
+}
+
+// scenarioModel values a company under a scenario before any terminal value.
+// It also returns the cash out per share in the final year of the horizon
+type scenarioModel func(v *valuator, ticker string, s Scenario, endYear ...int) (float64, float64, error)
+
+// Models that are run for every scenario in the order they are reported.
+// The DCF models start from the tangible book value of the tickers set with
+// SetTangibleBookValue so there is no separate tangible model
+var scenarioModels = []ValuationModel{
+	ModelDCFTrend,
+	ModelFCFTrend,
+	ModelOwnerEarnings,
+	ModelEPV,
+}
+
+var scenarioModelFuncs = map[ValuationModel]scenarioModel{
+	ModelDCFTrend: func(v *valuator, ticker string, s Scenario, endYear ...int) (float64, float64, error) {
+		bv, div, err := v.dcfTrendInputs(ticker, s.Trend, endYear...)
+		if err != nil {
+			return 0, 0, err
+		}
+		return v.scenarioDCF(ticker, s, bv, div, endYear...)
+	},
+	ModelFCFTrend: func(v *valuator, ticker string, s Scenario, endYear ...int) (float64, float64, error) {
+		bv, div, err := v.fcfTrendInputs(ticker, s.Trend, endYear...)
+		if err != nil {
+			return 0, 0, err
+		}
+		return v.scenarioDCF(ticker, s, bv, div, endYear...)
+	},
+	ModelOwnerEarnings: func(v *valuator, ticker string, s Scenario, endYear ...int) (float64, float64, error) {
+		return v.discountedOwnerEarnings(ticker, s.DiscountRate, s.Trend, s.Duration, endYear...)
+	},
+	// The EPV assumes no growth so the trend does not apply. It is already a
+	// perpetuity so there is no cash out for a terminal value
+	ModelEPV: func(v *valuator, ticker string, s Scenario, endYear ...int) (float64, float64, error) {
+		tax, maintenance := float64(defaultTaxRate), float64(defaultMaintenance)
+		if s.TaxRate != nil {
+			tax = *s.TaxRate
+		}
+		if s.Maintenance != nil {
+			maintenance = *s.Maintenance
+		}
+		e, err := v.EarningsPowerValue(ticker, s.DiscountRate, tax, maintenance, s.Duration, endYear...)
+		if err != nil {
+			return 0, 0, err
+		}
+		return e.PerShare(), 0, nil
+	},
+}
+
+// ScenarioMatrix provides an interface to the valuations of each model under
+// each scenario
+type ScenarioMatrix interface {
+	Scenarios() []string
+	Models() []ValuationModel
+	Value(scenario string, model ValuationModel) (float64, error)
+	// Weighted is the probability weighted value of a model over all scenarios
+	Weighted(model ValuationModel) (float64, error)
+	String() string
+}
+
+type scenarioMatrix struct {
+	names  []string
+	models []ValuationModel
+	Values map[string]map[ValuationModel]float64 `json:"Scenarios"`
+	Wgt    map[ValuationModel]float64            `json:"Weighted,omitempty"`
+}
+
+func (s scenarioMatrix) String() string {
+	data, err := json.MarshalIndent(s, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling scenario data: ", err)
+	}
+	return string(data)
+}
+
+// LoadScenarios reads a JSON list of scenarios
+func LoadScenarios(r io.Reader) ([]Scenario, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var scenarios []Scenario
+	if err = json.Unmarshal(data, &scenarios); err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	for _, s := range scenarios {
+		if s.Name == "" {
+			return nil, errors.New("Scenario is missing a name")
+		}
+		if names[s.Name] {
+			return nil, errors.New("Duplicate scenario " + s.Name)
+		}
+		names[s.Name] = true
+	}
+	return scenarios, nil
+}
+
+// scenarioDCF is DiscountedCashFlow along with the cash out (BV increase and
+// dividend) in the final year of the horizon
+func (v *valuator) scenarioDCF(ticker string, s Scenario, bv float64, div float64, endYear ...int) (float64, float64, error) {
+	val, err := v.DiscountedCashFlow(ticker, s.DiscountRate, bv, div, s.Duration, endYear...)
+	if err != nil {
+		return 0, 0, err
+	}
+	meas := v.Valuations[ticker].FiledData
+	if len(endYear) == 1 {
+		meas = createMeasuresList(meas, endYear[0])
+	}
+	cashOut := meas[len(meas)-1].DividendPerShare() + div*float64(s.Duration) + bv
+	return val, cashOut, nil
+}
+
+// terminalValue is the final year cash out grown in perpetuity at the
+// terminal growth of the scenario and discounted back over the horizon
+func terminalValue(s Scenario, cashOut float64) (float64, error) {
+	g := *s.TerminalGrowth
+	if g >= s.DiscountRate {
+		return 0, errors.New("Terminal growth should be less than the discount rate in " + s.Name)
+	}
+	tv := cashOut * (1 + g/100) / ((s.DiscountRate - g) / 100)
+	return tv / math.Pow(1+(s.DiscountRate/100), float64(s.Duration)), nil
+}
+
+func (v *valuator) ScenarioValuations(ticker string, scenarios []Scenario, endYear ...int) (ScenarioMatrix, error) {
+	if len(scenarios) == 0 {
+		return nil, errors.New("No scenarios to value " + ticker)
+	}
+	if _, ok := v.Valuations[ticker]; !ok {
+		return nil, errors.New("Valuator has not be told to collect data on " + ticker)
+	}
+
+	sm := &scenarioMatrix{
+		models: scenarioModels,
+		Values: make(map[string]map[ValuationModel]float64),
+	}
+	var totalWeight float64
+	for _, s := range scenarios {
+		if _, ok := sm.Values[s.Name]; ok {
+			return nil, errors.New("Duplicate scenario " + s.Name)
+		}
+		sm.names = append(sm.names, s.Name)
+		sm.Values[s.Name] = make(map[ValuationModel]float64)
+		for _, model := range scenarioModels {
+			val, cashOut, err := scenarioModelFuncs[model](v, ticker, s, endYear...)
+			if err != nil {
+				return nil, err
+			}
+			if s.TerminalGrowth != nil && model != ModelEPV {
+				tv, err := terminalValue(s, cashOut)
+				if err != nil {
+					return nil, err
+				}
+				val = round(val + tv)
+			}
+			sm.Values[s.Name][model] = val
+		}
+		totalWeight += s.Weight
+	}
+
+	if totalWeight > 0 {
+		sm.Wgt = make(map[ValuationModel]float64)
+		for _, model := range scenarioModels {
+			var sum float64
+			for _, s := range scenarios {
+				sum += s.Weight * sm.Values[s.Name][model]
+			}
+			sm.Wgt[model] = round(sum / totalWeight)
+		}
+	}
+	return sm, nil
+}
+
+func (s *scenarioMatrix) Scenarios() []string {
+	return s.names
+}
+
+func (s *scenarioMatrix) Models() []ValuationModel {
+	return s.models
+}
+
+func (s *scenarioMatrix) Value(scenario string, model ValuationModel) (float64, error) {
+	if vals, ok := s.Values[scenario]; ok {
+		if val, ok := vals[model]; ok {
+			return val, nil
+		}
+		return 0, errors.New("No valuation for model " + string(model))
+	}
+	return 0, errors.New("No valuation for scenario " + scenario)
+}
+
+func (s *scenarioMatrix) Weighted(model ValuationModel) (float64, error) {
+	if s.Wgt == nil {
+		return 0, errors.New("No scenario weights were given")
+	}
+	if val, ok := s.Wgt[model]; ok {
+		return val, nil
+	}
+	return 0, errors.New("No valuation for model " + string(model))
+}
@@ -0,0 +1,157 @@
+package valuator
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"math"
+	"strconv"
+)
+
+// SensitivityParam is a type definition for the DCF inputs that can be swept
+type SensitivityParam string
+
+// SensitivityDiscountRate sweeps the discount rate of the DCF
+const SensitivityDiscountRate SensitivityParam = "Discount Rate"
+
+// SensitivityTrend sweeps the % of trend factored into the DCF
+const SensitivityTrend SensitivityParam = "Trend"
+
+// SensitivityDuration sweeps the duration over which the CF is discounted
+const SensitivityDuration SensitivityParam = "Duration"
+
+// Sensitivity provides an interface to a grid of DCF values swept over two inputs
+type Sensitivity interface {
+	RowParam() SensitivityParam
+	ColumnParam() SensitivityParam
+	Rows() []float64
+	Columns() []float64
+	// Values is indexed by row and then column
+	Values() [][]float64
+	// CSV writes the grid with the column values as the header
+	CSV(io.Writer) error
+	String() string
+}
+
+type sensitivity struct {
+	RowP  SensitivityParam `json:"Row Parameter"`
+	ColP  SensitivityParam `json:"Column Parameter"`
+	RowV  []float64        `json:"Rows"`
+	ColV  []float64        `json:"Columns"`
+	Table [][]float64      `json:"Values"`
+}
+
+func (s sensitivity) String() string {
+	data, err := json.MarshalIndent(s, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling sensitivity data: ", err)
+	}
+	return string(data)
+}
+
+// dcfInputs are the inputs to DiscountedCashFlowTrend that can be swept
+type dcfInputs struct {
+	dr       float64
+	trend    float64
+	duration int
+}
+
+func (d *dcfInputs) set(param SensitivityParam, val float64) error {
+	switch param {
+	case SensitivityDiscountRate:
+		d.dr = val
+	case SensitivityTrend:
+		d.trend = val
+	case SensitivityDuration:
+		// The DCF is discounted over whole years
+		if val <= 0 || val != math.Trunc(val) {
+			return errors.New("Duration of " + strconv.FormatFloat(val, 'f', -1, 64) + " is not a positive whole number of years")
+		}
+		d.duration = int(val)
+	default:
+		return errors.New("Unsupported sensitivity parameter " + string(param))
+	}
+	return nil
+}
+
+func (v *valuator) DCFSensitivity(ticker string, dr float64, trend float64, duration int,
+	rowParam SensitivityParam, rows []float64,
+	colParam SensitivityParam, cols []float64, endYear ...int) (Sensitivity, error) {
+
+	if rowParam == colParam {
+		return nil, errors.New("Sensitivity needs two different parameters to sweep")
+	}
+	if len(rows) == 0 || len(cols) == 0 {
+		return nil, errors.New("Sensitivity needs values to sweep for both parameters")
+	}
+
+	s := &sensitivity{
+		RowP:  rowParam,
+		ColP:  colParam,
+		RowV:  rows,
+		ColV:  cols,
+		Table: make([][]float64, len(rows)),
+	}
+	for i, r := range rows {
+		s.Table[i] = make([]float64, len(cols))
+		for j, c := range cols {
+			in := dcfInputs{dr: dr, trend: trend, duration: duration}
+			if err := in.set(rowParam, r); err != nil {
+				return nil, err
+			}
+			if err := in.set(colParam, c); err != nil {
+				return nil, err
+			}
+			val, err := v.DiscountedCashFlowTrend(ticker, in.dr, in.trend, in.duration, endYear...)
+			if err != nil {
+				return nil, err
+			}
+			s.Table[i][j] = val
+		}
+	}
+	return s, nil
+}
+
+func (s *sensitivity) RowParam() SensitivityParam {
+	return s.RowP
+}
+
+func (s *sensitivity) ColumnParam() SensitivityParam {
+	return s.ColP
+}
+
+func (s *sensitivity) Rows() []float64 {
+	return s.RowV
+}
+
+func (s *sensitivity) Columns() []float64 {
+	return s.ColV
+}
+
+func (s *sensitivity) Values() [][]float64 {
+	return s.Table
+}
+
+func (s *sensitivity) CSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{string(s.RowP) + " \\ " + string(s.ColP)}
+	for _, c := range s.ColV {
+		header = append(header, strconv.FormatFloat(c, 'f', -1, 64))
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for i, r := range s.RowV {
+		record := []string{strconv.FormatFloat(r, 'f', -1, 64)}
+		for _, val := range s.Table[i] {
+			record = append(record, strconv.FormatFloat(val, 'f', 2, 64))
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
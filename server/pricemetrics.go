@@ -0,0 +1,44 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/palafrank/valuator"
+)
+
+// priceRatio is a price based ratio as shown on the valuator page
+type priceRatio struct {
+	Name  string
+	Value string
+}
+
+// priceRatios lists the ratios that can fail for a lack of data. The ones
+// that could not be computed are shown as NA
+func priceRatios(pm valuator.PriceBasedMetrics) []priceRatio {
+	if pm == nil {
+		return nil
+	}
+	ratios := []struct {
+		name string
+		fn   func() (float64, error)
+	}{
+		{"P/B", pm.PriceToBook},
+		{"P/TBV", pm.PriceToTangibleBook},
+		{"EV/Rev", pm.EVToRevenue},
+		{"EV/OI", pm.EVToOperatingIncome},
+		{"EarningsYield(%)", pm.EarningsYield},
+		{"FCFYield(%)", pm.FCFYield},
+		{"DivYield(%)", pm.DividendYield},
+		{"PEG", pm.PEG},
+		{"ShareholderYield(%)", pm.ShareholderYield},
+	}
+	var ret []priceRatio
+	for _, r := range ratios {
+		val := "NA"
+		if v, err := r.fn(); err == nil {
+			val = strconv.FormatFloat(v, 'f', 2, 64)
+		}
+		ret = append(ret, priceRatio{Name: r.name, Value: val})
+	}
+	return ret
+}
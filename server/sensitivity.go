@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/palafrank/valuator"
+)
+
+var (
+	sensitivityRates  = []float64{2, 3, 4, 5, 6}
+	sensitivityTrends = []float64{25, 50, 100, 125, 150}
+)
+
+func parseSweep(val string, def []float64) ([]float64, error) {
+	if val == "" {
+		return def, nil
+	}
+	var ret []float64
+	for _, s := range strings.Split(val, ",") {
+		f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, f)
+	}
+	return ret, nil
+}
+
+// sensitivity serves the DCF sensitivity grid for a collected ticker as CSV
+// or JSON. Discount rates and trends can be given as comma separated lists
+func (s *server) sensitivity(w http.ResponseWriter, r *http.Request) {
+	ticker := r.FormValue("ticker")
+	if err := s.valuator.Collect(ticker); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	rates, err := parseSweep(r.FormValue("dr"), sensitivityRates)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	trends, err := parseSweep(r.FormValue("trend"), sensitivityTrends)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sens, err := s.valuator.DCFSensitivity(ticker, 3, 100, 10,
+		valuator.SensitivityDiscountRate, rates,
+		valuator.SensitivityTrend, trends)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.FormValue("format") == "csv" {
+		// Write the CSV out only once all of it is ready so a failure can
+		// still be reported
+		var buf bytes.Buffer
+		if err = sens.CSV(&buf); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "text/csv")
+		w.Write(buf.Bytes())
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(sens.String()))
+}
@@ -20,6 +20,7 @@ type server struct {
 
 func (s *server) registerHandlers() {
 	http.HandleFunc("/", s.queryForm)
+	http.HandleFunc("/sensitivity", s.sensitivity)
 }
 
 func (s *server) createAndRunServer(url string, port string) error {
@@ -57,6 +58,17 @@ func (s *server) setupTemplates() {
 			ret, _ := s.valuator.DiscountedFCFTrend(ticker, dr, trend, duration)
 			return ret
 		},
+		"dcfOETrend": func(ticker string, dr float64, duration int, trend float64) float64 {
+			ret, _ := s.valuator.DiscountedOwnerEarnings(ticker, dr, trend, duration)
+			return ret
+		},
+		"priceRatios": priceRatios,
+		"sensitivity": func(ticker string) valuator.Sensitivity {
+			ret, _ := s.valuator.DCFSensitivity(ticker, 3, 100, 10,
+				valuator.SensitivityDiscountRate, sensitivityRates,
+				valuator.SensitivityTrend, sensitivityTrends)
+			return ret
+		},
 	}).ParseFiles("valuator.html")
 	if err != nil {
 		panic(err.Error())
@@ -56,5 +56,45 @@ func TestValuatorQuery(t *testing.T) {
 		t.Error("Should contain HTML table")
 		return
 	}
+	if !strings.Contains(string(data), `Piotroski F-Score`) {
+		t.Error("Should contain the F-score table")
+		return
+	}
+	if !strings.Contains(string(data), `DuPont`) {
+		t.Error("Should contain the DuPont table")
+		return
+	}
 
 }
+
+func TestSensitivityQuery(t *testing.T) {
+
+	s, err := newServer(valuatorDatabaseURL, valuatorDatabaseType)
+	if err != nil {
+		panic("Failed to create server")
+	}
+	ts := httptest.NewServer(http.HandlerFunc(s.sensitivity))
+
+	client := ts.Client()
+
+	form := make(url.Values)
+	form.Add("ticker", "IBM")
+	form.Add("format", "csv")
+	form.Add("dr", "2,3")
+	res, err := client.PostForm(ts.URL, form)
+	if err != nil {
+		t.Error("Failed to get response from valuator server ", err.Error())
+		return
+	}
+
+	data, err := ioutil.ReadAll(res.Body)
+	res.Body.Close()
+	if err != nil {
+		t.Error("Failed to read sensitivity from valuator server ", err.Error())
+		return
+	}
+
+	if strings.Count(string(data), "\n") != 3 {
+		t.Error("Should contain a header and a row per discount rate ", string(data))
+	}
+}
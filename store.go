@@ -6,6 +6,7 @@ import (
 	"io"
 	"io/ioutil"
 	"log"
+	"sort"
 )
 
 // MarshaledData is a wrapper of already marshalled data
@@ -66,6 +67,7 @@ type Store interface {
 	putMeasures(string, []byte)
 	write() error
 	read(string) error
+	tickers() []string
 	String() string
 }
 
@@ -152,3 +154,26 @@ func (s *storeCollection) read(ticker string) error {
 	s.Entries[ticker] = se
 	return nil
 }
+
+// tickers lists the tickers in the store along with those in the database
+// when the database can list them
+func (s *storeCollection) tickers() []string {
+	seen := make(map[string]bool)
+	var ret []string
+	for ticker := range s.Entries {
+		seen[ticker] = true
+		ret = append(ret, ticker)
+	}
+	if lister, ok := s.db.(TickerLister); ok {
+		if dbTickers, err := lister.Tickers(); err == nil {
+			for _, ticker := range dbTickers {
+				if !seen[ticker] {
+					seen[ticker] = true
+					ret = append(ret, ticker)
+				}
+			}
+		}
+	}
+	sort.Strings(ret)
+	return ret
+}
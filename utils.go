@@ -11,7 +11,6 @@ import (
 
 var (
 	priceURLFormatString = `https://api.iextrading.com/1.0/stock/%s/price`
-	historicalPrice      = `https://api.iextrading.com/1.0/stock/%s/chart/date/%s`
 )
 
 func round(val float64) float64 {
@@ -7,61 +7,86 @@ import (
 
 func (v *valuator) DiscountedCashFlowTrend(ticker string, dr float64, trend float64, duration int, endYear ...int) (float64, error) {
 
+	bv, div, err := v.dcfTrendInputs(ticker, trend, endYear...)
+	if err != nil {
+		return 0, err
+	}
+	return v.DiscountedCashFlow(ticker, dr, bv, div, duration, endYear...)
+}
+
+func (v *valuator) DiscountedFCFTrend(ticker string, dr float64, trend float64, duration int, endYear ...int) (float64, error) {
+
+	bv, div, err := v.fcfTrendInputs(ticker, trend, endYear...)
+	if err != nil {
+		return 0, err
+	}
+	return v.DiscountedCashFlow(ticker, dr, bv, div, duration, endYear...)
+}
+
+// dcfTrendInputs gets the BV and DIV rates of change for DiscountedCashFlowTrend
+func (v *valuator) dcfTrendInputs(ticker string, trend float64, endYear ...int) (float64, float64, error) {
+
 	if len(endYear) > 1 {
-		return 0, errors.New("Specify only one end year for DCF calculation")
+		return 0, 0, errors.New("Specify only one end year for DCF calculation")
 	}
 
 	// First get the right parameters for the DCF calculations
 	vals, ok := v.Valuations[ticker]
 	if !ok {
-		return 0, errors.New("Valuator has not be told to collect data on " + ticker)
+		return 0, 0, errors.New("Valuator has not be told to collect data on " + ticker)
 	}
 
-	div := vals.Avgs.AvgDividendGrowth()
-	bv := vals.Avgs.AvgBookValueGrowth()
-
+	avgs := vals.Avgs
 	if len(endYear) == 1 {
 		meas := createMeasuresList(vals.FiledData, endYear[0])
-		if avgs, err := newAverages(meas); err == nil {
-			div = avgs.AvgDividendGrowth()
-			bv = avgs.AvgBookValueGrowth()
-		} else {
-			return 0, err
+		var err error
+		if avgs, err = newAverages(meas); err != nil {
+			return 0, 0, err
 		}
 	}
+	div := avgs.AvgDividendGrowth()
+	bv := avgs.AvgBookValueGrowth()
+	if v.tangible[ticker] {
+		bv = avgs.AvgTangibleBookValueGrowth()
+	}
 
 	// Now adjust for trend
 	div = div * (trend / 100)
 	bv = bv * (trend / 100)
 
-	return v.DiscountedCashFlow(ticker, dr, bv, div, duration, endYear...)
+	return bv, div, nil
 }
 
-func (v *valuator) DiscountedFCFTrend(ticker string, dr float64, trend float64, duration int, endYear ...int) (float64, error) {
+// fcfTrendInputs gets the BV and DIV rates of change for DiscountedFCFTrend
+func (v *valuator) fcfTrendInputs(ticker string, trend float64, endYear ...int) (float64, float64, error) {
 
 	if len(endYear) > 1 {
-		return 0, errors.New("Specify only one end year for DCF calculation")
+		return 0, 0, errors.New("Specify only one end year for DCF calculation")
 	}
 	// First get the right parameters for the DCF calculations
 	vals, ok := v.Valuations[ticker]
 	if !ok {
-		return 0, errors.New("Valuator has not be told to collect data on " + ticker)
+		return 0, 0, errors.New("Valuator has not be told to collect data on " + ticker)
 	}
 	div := vals.Avgs.AvgDividendGrowth()
 	fcf := vals.Avgs.AvgCashFlowGrowth()
 	// Get the latest book value
-	bv := vals.FiledData[len(vals.FiledData)-1].BookValue()
+	last := vals.FiledData[len(vals.FiledData)-1]
 
 	if len(endYear) == 1 {
 		meas := createMeasuresList(vals.FiledData, endYear[0])
 		if avgs, err := newAverages(meas); err == nil {
 			div = avgs.AvgDividendGrowth()
 			fcf = avgs.AvgCashFlowGrowth()
-			bv = meas[len(meas)-1].BookValue()
+			last = meas[len(meas)-1]
 		} else {
-			return 0, err
+			return 0, 0, err
 		}
 	}
+	bv := last.BookValue()
+	if v.tangible[ticker] {
+		bv = last.TangibleBookValue()
+	}
 
 	// Now adjust for trend
 	div = div * (trend / 100)
@@ -70,7 +95,13 @@ func (v *valuator) DiscountedFCFTrend(ticker string, dr float64, trend float64,
 	// Get the BV growth at the rate of FCF growth
 	bv = bv * (fcf / 100)
 
-	return v.DiscountedCashFlow(ticker, dr, bv, div, duration, endYear...)
+	return bv, div, nil
+}
+
+// SetTangibleBookValue makes the DCF of the ticker start from the tangible
+// book value so acquired goodwill and intangibles are not valued
+func (v *valuator) SetTangibleBookValue(ticker string, tangible bool) {
+	v.tangible[ticker] = tangible
 }
 
 func (v *valuator) DiscountedCashFlow(ticker string, dr float64, bvIn float64, divIn float64, duration int, endYear ...int) (float64, error) {
@@ -87,13 +118,15 @@ func (v *valuator) DiscountedCashFlow(ticker string, dr float64, bvIn float64, d
 	bv := bvIn
 
 	// Start with the latest value
-	outDiv := vals.FiledData[len(vals.FiledData)-1].DividendPerShare()
-	outBv := vals.FiledData[len(vals.FiledData)-1].BookValue()
-
+	last := vals.FiledData[len(vals.FiledData)-1]
 	if len(endYear) == 1 {
 		meas := createMeasuresList(vals.FiledData, endYear[0])
-		outDiv = meas[len(meas)-1].DividendPerShare()
-		outBv = meas[len(meas)-1].BookValue()
+		last = meas[len(meas)-1]
+	}
+	outDiv := last.DividendPerShare()
+	outBv := last.BookValue()
+	if v.tangible[ticker] {
+		outBv = last.TangibleBookValue()
 	}
 
 	sumDiv := outDiv
@@ -117,3 +150,51 @@ func (v *valuator) DiscountedCashFlow(ticker string, dr float64, bvIn float64, d
 
 	return round(sumDiv + sumBv), nil
 }
+
+func (v *valuator) DiscountedOwnerEarnings(ticker string, dr float64, trend float64, duration int, endYear ...int) (float64, error) {
+	val, _, err := v.discountedOwnerEarnings(ticker, dr, trend, duration, endYear...)
+	return val, err
+}
+
+// discountedOwnerEarnings also returns the owner earnings per share in the
+// final year of the duration
+func (v *valuator) discountedOwnerEarnings(ticker string, dr float64, trend float64, duration int, endYear ...int) (float64, float64, error) {
+
+	if len(endYear) > 1 {
+		return 0, 0, errors.New("Specify only one end year for DCF calculation")
+	}
+	vals, ok := v.Valuations[ticker]
+	if !ok {
+		return 0, 0, errors.New("Valuator has not be told to collect data on " + ticker)
+	}
+	meas := vals.FiledData
+	growth := vals.Avgs.AvgOwnerEarningsGrowth()
+
+	if len(endYear) == 1 {
+		meas = createMeasuresList(vals.FiledData, endYear[0])
+		if avgs, err := newAverages(meas); err == nil {
+			growth = avgs.AvgOwnerEarningsGrowth()
+		} else {
+			return 0, 0, err
+		}
+	}
+
+	last := meas[len(meas)-1]
+	sc, err := last.Filing().ShareCount()
+	if err != nil {
+		return 0, 0, err
+	}
+	oe := last.OwnerEarnings() / sc
+
+	// Now adjust for trend
+	growth = growth * (trend / 100)
+
+	// Book value at the end along with the owner earnings each year
+	sum := last.BookValue() / math.Pow(1+(dr/100), float64(duration))
+	for i := 1; i <= duration; i++ {
+		oe = oe * (1 + growth/100)
+		sum += oe / math.Pow(1+(dr/100), float64(i))
+	}
+
+	return round(sum), oe, nil
+}
@@ -1,9 +1,13 @@
 package valuator
 
+import "time"
+
 // Valuator interface allows queries into the the valuator for valuation metrics
 type Valuator interface {
 
 	// Interfaces to calculate valuation of a Company
+	// An optional endYear values the company with the filings of the fiscal
+	// years up to and including it
 
 	/*
 		 	DiscountedCashFlowTrend
@@ -44,6 +48,112 @@ type Valuator interface {
 			 duration: Time over which to discount the CF
 	*/
 	DiscountedFCFTrend(ticker string, dr float64, trend float64, duration int, endYear ...int) (float64, error)
+	/*
+		 	DiscountedOwnerEarnings
+			Calculated DCF based on the owner earnings per share and the
+			collected owner earnings growth rate instead of BV growth
+			trend will adjust the growth rate based on user Input
+			Input:
+		   ticker: ticker of the company
+		   dr: Discount rate for DCF calculations
+		   trend: % of the averages to factor in DCF calculations
+			 duration: Time over which to discount the CF
+	*/
+	DiscountedOwnerEarnings(ticker string, dr float64, trend float64, duration int, endYear ...int) (float64, error)
+	/*
+		 	ImpliedGrowth
+			Reverse DCF that solves for the FCF growth rate (%) at which the
+			DiscountedFCFTrend value equals the current market price
+			Input:
+		   ticker: ticker of the company
+		   dr: Discount rate for DCF calculations
+			 duration: Time over which to discount the CF
+	*/
+	ImpliedGrowth(ticker string, dr float64, duration int, endYear ...int) (ImpliedRate, error)
+	/*
+		 	ImpliedDiscountRate
+			Reverse DCF that solves for the discount rate (%) at which the
+			DiscountedFCFTrend value equals the current market price
+			Input:
+		   ticker: ticker of the company
+		   trend: % of the averages to factor in DCF calculations
+			 duration: Time over which to discount the CF
+	*/
+	ImpliedDiscountRate(ticker string, trend float64, duration int, endYear ...int) (ImpliedRate, error)
+	/*
+		 	DCFSensitivity
+			Grid of DiscountedCashFlowTrend values with two of the inputs swept
+			over the given values. The input that is not swept is taken from
+			dr, trend or duration
+			Input:
+		   ticker: ticker of the company
+		   dr: Discount rate for DCF calculations
+		   trend: % of the averages to factor in DCF calculations
+			 duration: Time over which to discount the CF
+			 rowParam, rows: Input and values to sweep along the rows
+			 colParam, cols: Input and values to sweep along the columns
+	*/
+	DCFSensitivity(ticker string, dr float64, trend float64, duration int,
+		rowParam SensitivityParam, rows []float64,
+		colParam SensitivityParam, cols []float64, endYear ...int) (Sensitivity, error)
+	/*
+		 	MonteCarlo
+			Simulates DiscountedFCFTrend style valuations with the FCF and DIV
+			growth drawn from the mean and dispersion of the YoY series and the
+			discount rate drawn from the user distribution
+			Input:
+		   ticker: ticker of the company
+		   drMean, drStdDev: Normal distribution of the discount rate
+			 duration: Time over which to discount the CF
+			 runs: Number of simulations
+			 seed: Seed for the random number generator
+	*/
+	MonteCarlo(ticker string, drMean float64, drStdDev float64, duration int,
+		runs int, seed int64, endYear ...int) (Simulation, error)
+	/*
+		 	ScenarioValuations
+			Runs every valuation model under each of the named scenarios
+			Scenarios can be loaded from a JSON file with LoadScenarios
+			A probability weighted value per model is reported when the
+			scenarios carry weights
+			Input:
+		   ticker: ticker of the company
+		   scenarios: Assumption sets to value the company with
+	*/
+	ScenarioValuations(ticker string, scenarios []Scenario, endYear ...int) (ScenarioMatrix, error)
+	/*
+		 	PriceRange
+			Low/mid/high intrinsic values across all valuation models compared
+			with the current market price for a margin of safety and a
+			buy/hold/avoid verdict
+			Input:
+		   ticker: ticker of the company
+		   opts: Assumptions and thresholds. See DefaultPriceRangeOptions
+	*/
+	PriceRange(ticker string, opts PriceRangeOptions) (IntrinsicRange, error)
+	/*
+		 	CostOfCapital
+			Cost of equity through CAPM and the WACC of the company weighted
+			by market capitalization and the debt on the last filing
+			The WACC can be used as the discount rate of every valuation method
+			Input:
+		   ticker: ticker of the company
+		   opts: Market assumptions. Beta is estimated from price history if 0
+	*/
+	CostOfCapital(ticker string, opts WACCOptions) (CostOfCapital, error)
+	/*
+		 	EarningsPowerValue
+			Greenwald's EPV per share from the operating income at the average
+			operating margin, adjusted for maintenance capex, excess cash and
+			debt. The growth value is the DiscountedFCFTrend value over the EPV
+			Input:
+		   ticker: ticker of the company
+		   dr: Discount rate for EPV and DCF calculations
+		   taxRate: Normalized tax rate in %
+		   maintenance: % of the average capex that is maintenance capex
+			 duration: Time over which to discount the CF for the growth value
+	*/
+	EarningsPowerValue(ticker string, dr float64, taxRate float64, maintenance float64, duration int, endYear ...int) (EarningsPower, error)
 
 	/* Per Ticker interface */
 
@@ -65,11 +175,68 @@ type Valuator interface {
 	// PriceMetrics gets the price based metrics computed based on current price
 	PriceMetrics(string) PriceBasedMetrics
 
+	// PriceHistoryMetrics gets the beta, volatility, drawdown and correlation
+	// of a ticker against a benchmark from the price history in the source
+	PriceHistoryMetrics(ticker string, benchmark string, src PriceSource) (PriceHistoryMetrics, error)
+
+	// MultiplesHistory gets the price based metrics of each filing at the
+	// close on the filing date from the source, with the percentile of the
+	// current multiples over that history
+	MultiplesHistory(ticker string, src PriceSource) (MultiplesHistory, error)
+
+	// NetCurrentAssetValue gets the NCAV and the liquidation value per share
+	// of a ticker after the haircuts for each asset class
+	NetCurrentAssetValue(ticker string, haircuts LiquidationHaircuts) (NetNet, error)
+
+	// CompanyValue converts a per share value from the DCF methods to the
+	// value of the whole company using the basic shares as of the end year.
+	// The DCF methods only value a basic share, there is no whole company DCF
+	CompanyValue(ticker string, perShare float64, endYear ...int) (float64, error)
+
+	// ShareValue converts a whole company value to a value per share on the
+	// basis as of the end year
+	ShareValue(ticker string, companyValue float64, basis ShareBasis, endYear ...int) (float64, error)
+
+	// SetReportingCurrency sets the ISO 4217 currency, ex: EUR, that a foreign
+	// filer reports in for the next Collect of the ticker. It is only used for
+	// the filings in an unknown currency. Filings are in the currency
+	// reported by the collector otherwise, USD for a 10-K and unknown for a
+	// 20-F or 40-F
+	SetReportingCurrency(ticker string, currency string) error
+
+	// SetADRRatio sets the number of ordinary shares one ADR of the ticker
+	// represents. The quoted ADR prices are divided by it so the price
+	// metrics and the DCF values are per ordinary share as in the filings
+	SetADRRatio(ticker string, ratio float64) error
+
+	// SetTangibleBookValue makes the DCF methods of the ticker start from and
+	// grow the tangible book value instead of the total book value so that
+	// goodwill and intangibles from acquisitions are not counted as value
+	SetTangibleBookValue(ticker string, tangible bool)
+
 	// Clean clears all the filing data collected for a specific ticker
 	Clean(string)
 
 	/* Overall Valuator interface */
 
+	// Backtest values the tickers as of each past filing with only the data
+	// known then and compares the values with the price that followed
+	Backtest(opts BacktestOptions) (Backtest, error)
+
+	// NetNetScreen lists the tickers in the store and database that trade
+	// below their NCAV per share, deepest discount first
+	NetNetScreen(haircuts LiquidationHaircuts) ([]NetNet, error)
+
+	// AsOf gets a view of the collected tickers with only the filings and the
+	// price known on the date so that historical analysis has no look-ahead.
+	// The view cannot collect or write to the database
+	AsOf(date time.Time, prices PriceSource) (Valuator, error)
+
+	// ReportIn gets a view of the collected tickers with the measures and
+	// price metrics converted to the ISO 4217 currency with the FX rates in
+	// the source. The view cannot collect or write to the database
+	ReportIn(currency string, rates FXRateSource) (Valuator, error)
+
 	// Write saves the entire data in the valuator to the underlying database
 	Write() error
 
@@ -89,6 +256,9 @@ func NewValuator(db Database) (Valuator, error) {
 		collector:  make(map[string]Collector),
 		Valuations: make(map[string]*valuation),
 		store:      newStore(db),
+		currencies: make(map[string]string),
+		adrRatios:  make(map[string]float64),
+		tangible:   make(map[string]bool),
 	}
 
 	return v, nil
@@ -7,8 +7,20 @@ import (
 	"time"
 )
 
-// Filing interface for fetching financial data
+// Filing interface for fetching financial data along with the fiscal period
+// the filing reports on and its currency
 type Filing interface {
+	ReportedFiling
+	// FiscalYear is the year the fiscal period is named by
+	FiscalYear() int
+	// PeriodEnd is the last day of the fiscal period
+	PeriodEnd() time.Time
+	// Currency is the ISO 4217 code of the currency the values are in
+	Currency() string
+}
+
+// ReportedFiling interface for fetching the data as reported by a collector
+type ReportedFiling interface {
 	Ticker() string
 	FiledOn() time.Time
 	ShareCount() (float64, error)
@@ -50,6 +62,36 @@ type valuator struct {
 	collector  map[string]Collector
 	Valuations map[string]*valuation `json:"Company"`
 	store      Store
+	currencies map[string]string
+	adrRatios  map[string]float64
+	tangible   map[string]bool
+}
+
+// newView creates an empty valuator for a view of the collected tickers. The
+// settings of the tickers are carried over but nothing is stored
+func (v *valuator) newView() (*valuator, error) {
+	db, err := NewDatabase(nil, NoneDatabaseType)
+	if err != nil {
+		return nil, err
+	}
+	view := &valuator{
+		collector:  make(map[string]Collector),
+		Valuations: make(map[string]*valuation),
+		store:      newStore(db),
+		currencies: make(map[string]string),
+		adrRatios:  make(map[string]float64),
+		tangible:   make(map[string]bool),
+	}
+	for ticker, currency := range v.currencies {
+		view.currencies[ticker] = currency
+	}
+	for ticker, ratio := range v.adrRatios {
+		view.adrRatios[ticker] = ratio
+	}
+	for ticker, tangible := range v.tangible {
+		view.tangible[ticker] = tangible
+	}
+	return view, nil
 }
 
 func (v valuator) String() string {
@@ -153,7 +195,9 @@ func (v *valuator) Collect(ticker string) error {
 		v.Clean(ticker)
 		return err
 	}
-	mea := newMeasures(fils)
+	fils = withDefaultCurrency(fils, v.currencies[ticker])
+	// Amended filings replace the values of the fiscal year they amend
+	mea := newMeasures(reconcileFilings(fils))
 
 	if err = newYoYs(mea); err != nil {
 		return err
@@ -168,7 +212,7 @@ func (v *valuator) Collect(ticker string) error {
 	valuation := v.Valuations[ticker]
 	valuation.FiledData = mea
 	valuation.Avgs = avg
-	valuation.Pbm = newPriceBasedMetrics(mea[len(mea)-1])
+	valuation.Pbm = newPriceBasedMetricsAt(mea[len(mea)-1], avg, v.sharePrice(ticker, priceFetcher(ticker)))
 	valuation.Date = Timestamp(time.Now())
 	v.Store()
 
@@ -1,8 +1,16 @@
 package valuator
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 var (
@@ -62,16 +70,16 @@ func TestNewPSXValuator(t *testing.T) {
 		t.Error("Failed to create a valuator: ", err.Error())
 	}
 
-	ret, _ := v.DiscountedCashFlowTrend("PSX", 3, 100, 10, 2018)
+	ret, _ := v.DiscountedCashFlowTrend("PSX", 3, 100, 10, 2017)
 	if ret != 192.95 {
 		t.Error("Error in DCF calculation at 100% trend ", ret)
 	}
 
-	ret, _ = v.DiscountedCashFlowTrend("PSX", 3, 50, 10, 2018)
+	ret, _ = v.DiscountedCashFlowTrend("PSX", 3, 50, 10, 2017)
 	if ret != 124.36 {
 		t.Error("Error in DCF calculation at 100% trend ", ret)
 	}
-	ret, _ = v.DiscountedCashFlowTrend("PSX", 3, 20, 10, 2018)
+	ret, _ = v.DiscountedCashFlowTrend("PSX", 3, 20, 10, 2017)
 	if ret != 83.2 {
 		t.Error("Error in DCF calculation at 100% trend ", ret)
 	}
@@ -92,12 +100,12 @@ func TestNewTGTValuator(t *testing.T) {
 		return
 	}
 
-	ret, _ := v.DiscountedCashFlowTrend("TGT", 3, 100, 10, 2018)
+	ret, _ := v.DiscountedCashFlowTrend("TGT", 3, 100, 10, 2017)
 	if ret != 27.72 {
 		t.Error("Error in DCF calculation at 100% trend ", ret)
 	}
 
-	ret, _ = v.DiscountedCashFlow("TGT", 3, 0.30, 0.23, 10, 2018)
+	ret, _ = v.DiscountedCashFlow("TGT", 3, 0.30, 0.23, 10, 2017)
 	if ret != 51.51 {
 		t.Error("Error in DCF calculation at 100% trend ", ret)
 	}
@@ -127,7 +135,7 @@ func TestNewIBMValuator(t *testing.T) {
 		return
 	}
 	fmt.Println(v)
-	ret, _ := v.DiscountedCashFlowTrend("IBM", 3, 100, 10, 2018)
+	ret, _ := v.DiscountedCashFlowTrend("IBM", 3, 100, 10, 2017)
 	if ret != 81.15 {
 		t.Error("Error in DCF calculation at 100% trend ", ret)
 	}
@@ -224,3 +232,1705 @@ func TestPriceFetcher(t *testing.T) {
 		t.Error("Error getting price of tickers ", aapl, pfe, dgx)
 	}
 }
+
+func TestImpliedRates(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+	val := v.(*valuator)
+
+	// Solving at the DCF value should give back the average growth
+	ret, err := val.impliedGrowth("CSCO", 29.51, 3, 10, 2018)
+	if err != nil || !ret.Converged() {
+		t.Error("Failed to solve for implied growth ", err)
+		return
+	}
+	avgs, _ := newAverages(createMeasuresList(v.Measures("CSCO"), 2018))
+	if math.Abs(ret.Rate()-avgs.AvgCashFlowGrowth()) > 0.5 {
+		t.Error("Error in implied growth calculation ", ret.Rate(), avgs.AvgCashFlowGrowth())
+	}
+
+	ret, err = val.impliedDiscountRate("CSCO", 29.51, 100, 10, 2018)
+	if err != nil || ret.Rate() < 2.9 || ret.Rate() > 3.1 {
+		t.Error("Error in implied discount rate calculation ", ret, err)
+	}
+
+	_, err = val.impliedGrowth("CSCO", 100000, 3, 10, 2018)
+	if err == nil {
+		t.Error("Error: Implied growth should not be found for an unreachable price")
+	}
+}
+
+func TestDCFSensitivity(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("PSX")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+
+	sens, err := v.DCFSensitivity("PSX", 3, 100, 10,
+		SensitivityDiscountRate, []float64{2, 3, 4},
+		SensitivityTrend, []float64{20, 50, 100}, 2017)
+	if err != nil {
+		t.Error("Failed to compute sensitivity: ", err.Error())
+		return
+	}
+	vals := sens.Values()
+	if vals[1][0] != 83.2 || vals[1][1] != 124.36 || vals[1][2] != 192.95 {
+		t.Error("Error in DCF sensitivity at 3% discount rate ", vals[1])
+	}
+	if vals[0][2] <= vals[1][2] || vals[2][2] >= vals[1][2] {
+		t.Error("Error: DCF value should fall as the discount rate rises ", vals)
+	}
+
+	by := bytes.NewBuffer(nil)
+	if err = sens.CSV(by); err != nil || strings.Count(by.String(), "\n") != 4 {
+		t.Error("Error writing sensitivity as CSV ", by.String())
+	}
+
+	_, err = v.DCFSensitivity("PSX", 3, 100, 10,
+		SensitivityTrend, []float64{50}, SensitivityTrend, []float64{100})
+	if err == nil {
+		t.Error("Error: Sensitivity should fail when sweeping the same parameter")
+	}
+	for _, d := range []float64{-5, 0, 7.5} {
+		_, err = v.DCFSensitivity("PSX", 3, 100, 10,
+			SensitivityDuration, []float64{d}, SensitivityTrend, []float64{100})
+		if err == nil {
+			t.Error("Error: Duration should be a positive whole number ", d)
+		}
+	}
+}
+
+func TestMonteCarlo(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+	val := v.(*valuator)
+
+	sim, err := val.monteCarlo("CSCO", 29.51, 3, 1, 10, 1000, 1, 2018)
+	if err != nil {
+		t.Error("Failed to run simulation: ", err.Error())
+		return
+	}
+	if sim.Percentile(5) > sim.Percentile(50) || sim.Percentile(50) > sim.Percentile(95) {
+		t.Error("Error: Percentiles should be ordered ", sim)
+	}
+	if sim.ProbabilityAbovePrice() <= 0 || sim.ProbabilityAbovePrice() >= 100 {
+		t.Error("Error in probability of value above price ", sim)
+	}
+
+	again, _ := val.monteCarlo("CSCO", 29.51, 3, 1, 10, 1000, 1, 2018)
+	if again.Mean() != sim.Mean() || again.Percentile(50) != sim.Percentile(50) {
+		t.Error("Error: Simulations with the same seed should match ", sim, again)
+	}
+}
+
+func TestScenarioValuations(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("PSX")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+
+	scenarios, err := LoadScenarios(strings.NewReader(`[
+		{"Name": "base", "Trend": 100, "Discount Rate": 3, "Duration": 10, "Weight": 1},
+		{"Name": "bear", "Trend": 50, "Discount Rate": 3, "Duration": 10, "Weight": 1},
+		{"Name": "bull", "Trend": 150, "Discount Rate": 3, "Duration": 10, "Terminal Growth": 1}
+	]`))
+	if err != nil {
+		t.Error("Failed to load scenarios: ", err.Error())
+		return
+	}
+
+	sm, err := v.ScenarioValuations("PSX", scenarios, 2017)
+	if err != nil {
+		t.Error("Failed to value scenarios: ", err.Error())
+		return
+	}
+	if ret, _ := sm.Value("base", ModelDCFTrend); ret != 192.95 {
+		t.Error("Error in base scenario DCF ", ret)
+	}
+	if ret, _ := sm.Value("bear", ModelDCFTrend); ret != 124.36 {
+		t.Error("Error in bear scenario DCF ", ret)
+	}
+	bull, _ := sm.Value("bull", ModelDCFTrend)
+	noTerminal, _ := v.DiscountedCashFlowTrend("PSX", 3, 150, 10, 2017)
+	if bull <= noTerminal {
+		t.Error("Error: Terminal value should add to the bull scenario ", bull, noTerminal)
+	}
+	if ret, _ := sm.Weighted(ModelDCFTrend); ret != 158.65 {
+		t.Error("Error in weighted scenario DCF ", ret)
+	}
+	// EPV has no growth to trend and no terminal value
+	e, _ := v.EarningsPowerValue("PSX", 3, 21, 100, 10, 2017)
+	for _, name := range sm.Scenarios() {
+		if ret, _ := sm.Value(name, ModelEPV); ret != e.PerShare() {
+			t.Error("Error in scenario EPV ", name, ret, e.PerShare())
+		}
+	}
+	tax, maintenance := 35.0, 50.0
+	scenarios[0].TaxRate, scenarios[0].Maintenance = &tax, &maintenance
+	sm, _ = v.ScenarioValuations("PSX", scenarios[:1], 2017)
+	e, _ = v.EarningsPowerValue("PSX", 3, tax, maintenance, 10, 2017)
+	if ret, _ := sm.Value("base", ModelEPV); ret != e.PerShare() {
+		t.Error("Error: Scenario EPV should use the tax rate and maintenance capex ", ret, e.PerShare())
+	}
+
+	_, err = LoadScenarios(strings.NewReader(`[{"Name": "base"}, {"Name": "base"}]`))
+	if err == nil {
+		t.Error("Error: Duplicate scenarios should not be loaded")
+	}
+}
+
+func TestPriceRange(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+	val := v.(*valuator)
+	opts := DefaultPriceRangeOptions()
+
+	pr, err := val.priceRange("CSCO", 1, opts)
+	if err != nil {
+		t.Error("Failed to get price range: ", err.Error())
+		return
+	}
+	if pr.Low() > pr.Mid() || pr.Mid() > pr.High() {
+		t.Error("Error: Price range should be ordered ", pr)
+	}
+	if pr.Verdict() != VerdictBuy {
+		t.Error("Error in verdict for a deep discount ", pr)
+	}
+
+	pr, _ = val.priceRange("CSCO", pr.Mid()*0.9, opts)
+	if pr.Verdict() != VerdictHold || pr.MarginOfSafety() < 9 || pr.MarginOfSafety() > 10 {
+		t.Error("Error in verdict for a small discount ", pr)
+	}
+
+	pr, _ = val.priceRange("CSCO", pr.Mid()*1.1, opts)
+	if pr.Verdict() != VerdictAvoid || pr.MarginOfSafety() >= 0 {
+		t.Error("Error in verdict for a premium ", pr)
+	}
+}
+
+func TestCostOfCapital(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+	val := v.(*valuator)
+
+	src, err := NewPriceSource("./testdata/prices/", CSVPriceSourceType)
+	if err != nil {
+		t.Error("Failed to create price source: ", err.Error())
+		return
+	}
+	opts := WACCOptions{
+		RiskFreeRate:      3,
+		EquityRiskPremium: 5,
+		Prices:            src,
+		Benchmark:         "SPY",
+		CostOfDebt:        4,
+		TaxRate:           25,
+	}
+	c, err := val.costOfCapital("CSCO", 200000000000, opts)
+	if err != nil {
+		t.Error("Failed to compute cost of capital: ", err.Error())
+		return
+	}
+	if c.Beta() < 1.1 || c.Beta() > 1.3 {
+		t.Error("Error in beta estimated from price history ", c.Beta())
+	}
+	if c.CostOfDebt() != 3 {
+		t.Error("Error in after-tax cost of debt ", c.CostOfDebt())
+	}
+	if c.WACC() <= c.CostOfDebt() || c.WACC() >= c.CostOfEquity() {
+		t.Error("Error: WACC should be between the cost of debt and equity ", c)
+	}
+
+	opts.Beta = 1
+	c, _ = val.costOfCapital("CSCO", 200000000000, opts)
+	if c.CostOfEquity() != 8 {
+		t.Error("Error in CAPM cost of equity ", c.CostOfEquity())
+	}
+
+	opts.Beta = 0
+	opts.Benchmark = "QQQ"
+	_, err = val.costOfCapital("CSCO", 200000000000, opts)
+	if err == nil {
+		t.Error("Error: Beta should not be estimated without benchmark prices")
+	}
+}
+
+func TestPriceHistoryMetrics(t *testing.T) {
+	v, _ := NewValuator(testFileDB)
+	src, err := NewPriceSource("./testdata/prices/", CSVPriceSourceType)
+	if err != nil {
+		t.Error("Failed to create price source: ", err.Error())
+		return
+	}
+
+	hist, err := v.PriceHistoryMetrics("CSCO", "SPY", src)
+	if err != nil {
+		t.Error("Failed to get price history metrics: ", err.Error())
+		return
+	}
+	if hist.Beta() < 1.1 || hist.Beta() > 1.3 {
+		t.Error("Error in beta ", hist.Beta())
+	}
+	if hist.Correlation() < 0.8 || hist.Correlation() > 1 {
+		t.Error("Error in correlation ", hist.Correlation())
+	}
+	if hist.Volatility() < 15 || hist.Volatility() > 25 {
+		t.Error("Error in annualized volatility ", hist.Volatility())
+	}
+	if hist.MaxDrawdown() <= 0 || hist.MaxDrawdown() >= 100 {
+		t.Error("Error in max drawdown ", hist.MaxDrawdown())
+	}
+
+	_, err = v.PriceHistoryMetrics("CSCO", "QQQ", src)
+	if err == nil {
+		t.Error("Error: Metrics should not be computed without benchmark prices")
+	}
+	_, err = NewPriceSource("./testdata/none/", CSVPriceSourceType)
+	if err == nil {
+		t.Error("Error: Price source should not be created for a missing folder")
+	}
+}
+
+func TestEarningsPowerValue(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+
+	e, err := v.EarningsPowerValue("CSCO", 8, 21, 100, 10, 2018)
+	if err != nil {
+		t.Error("Failed to compute EPV: ", err.Error())
+		return
+	}
+	if e.NormalizedMargin() <= 0 || e.MaintenanceCapex() <= 0 || e.PerShare() <= 0 {
+		t.Error("Error in EPV calculation ", e)
+	}
+	dcf, _ := v.DiscountedFCFTrend("CSCO", 8, 100, 10, 2018)
+	if math.Abs(dcf-e.PerShare()-e.GrowthValue()) > 0.02 {
+		t.Error("Error: Growth value should be the DCF over the EPV ", dcf, e)
+	}
+
+	higher, _ := v.EarningsPowerValue("CSCO", 10, 21, 100, 10, 2018)
+	if higher.PerShare() >= e.PerShare() {
+		t.Error("Error: EPV should fall as the discount rate rises ", higher, e)
+	}
+}
+
+func TestOwnerEarnings(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+
+	// FY2013: 12894M operating cash flow less 1160M capex
+	m := v.Measures("CSCO")
+	if m[1].OwnerEarnings() != 11734000000 {
+		t.Error("Owner earnings was not the expected value", m[1].OwnerEarnings())
+	}
+	// Up from 10365M in FY2012 on the same definition
+	if m[0].OwnerEarnings() != 10365000000 || m[1].Yoy().OwnerEarningsGrowth() != 13 {
+		t.Error("Error in owner earnings growth ", m[1].Yoy().OwnerEarningsGrowth())
+	}
+
+	low, err := v.DiscountedOwnerEarnings("CSCO", 3, 50, 10, 2018)
+	if err != nil {
+		t.Error("Failed to discount owner earnings: ", err.Error())
+		return
+	}
+	high, _ := v.DiscountedOwnerEarnings("CSCO", 3, 150, 10, 2018)
+	if low <= 0 || (high-low)*v.Averages("CSCO").AvgOwnerEarningsGrowth() < 0 {
+		t.Error("Error in owner earnings DCF ", low, high)
+	}
+}
+
+func TestNetCurrentAssetValue(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+
+	f := v.LastFiling("CSCO")
+	ca, _ := f.CurrentAssets()
+	cl, _ := f.CurrentLiabilities()
+	ld, _ := f.LongTermDebt()
+	sc, _ := f.ShareCount()
+	n, err := v.NetCurrentAssetValue("CSCO", DefaultLiquidationHaircuts())
+	if err != nil {
+		t.Error("Failed to compute NCAV: ", err.Error())
+		return
+	}
+	if n.NCAVPerShare() != round((ca-cl-ld)/sc) {
+		t.Error("NCAV was not the expected value", n.NCAVPerShare(), round((ca-cl-ld)/sc))
+	}
+	if n.LiquidationValue() >= n.NCAVPerShare() {
+		t.Error("Error: Liquidation value after haircuts should be below NCAV ", n)
+	}
+
+	net, _ := newNetNet(f, n.NCAVPerShare()/2, DefaultLiquidationHaircuts())
+	if n.NCAVPerShare() > 0 && net.Discount() != 50 {
+		t.Error("Error in discount to NCAV ", net)
+	}
+
+	tickers := strings.Join(v.(*valuator).store.tickers(), ",")
+	if !strings.Contains(tickers, "CSCO") || !strings.Contains(tickers, "PSX") {
+		t.Error("Error listing tickers in the database ", tickers)
+	}
+}
+
+func TestNetNetScreen(t *testing.T) {
+	// CSCO is the only company in the database with a positive NCAV so its
+	// filings are copied under other tickers to price them differently
+	dir, err := ioutil.TempDir("", "netnet")
+	if err != nil {
+		t.Error("Failed to create database folder: ", err.Error())
+		return
+	}
+	defer os.RemoveAll(dir)
+	files := map[string]string{"AAA": "CSCO", "BBB": "CSCO", "CCC": "CSCO", "DDD": "IBM"}
+	for ticker, src := range files {
+		data, err := ioutil.ReadFile("./db/" + src + ".json")
+		if err != nil {
+			t.Error("Failed to read the database: ", err.Error())
+			return
+		}
+		ioutil.WriteFile(dir+"/"+ticker+".json", data, 0644)
+	}
+	db, err := NewDatabase(dir+"/", FileDatabaseType)
+	if err != nil {
+		t.Error("Failed to create database: ", err.Error())
+		return
+	}
+	v, _ := NewValuator(db)
+	val := v.(*valuator)
+
+	// NCAV of CSCO is 3.16 per share. CCC trades above it and IBM has a
+	// negative NCAV
+	prices := map[string]float64{"AAA": 2.5, "BBB": 1.5, "CCC": 5, "DDD": 1}
+	for ticker, price := range prices {
+		if err = v.Collect(ticker); err != nil {
+			t.Error("Failed to create a valuator: ", err.Error())
+			return
+		}
+		vals := val.Valuations[ticker]
+		vals.Pbm = newPriceBasedMetricsAt(vals.FiledData[len(vals.FiledData)-1], vals.Avgs, price)
+	}
+
+	nets, err := v.NetNetScreen(DefaultLiquidationHaircuts())
+	if err != nil || len(nets) != 2 {
+		t.Error("Error: Only AAA and BBB should trade below NCAV ", nets, err)
+		return
+	}
+	if nets[0].Price() != 1.5 || nets[1].Price() != 2.5 || nets[0].Discount() <= nets[1].Discount() {
+		t.Error("Error: Deepest discount should be first ", nets)
+	}
+	if nets[0].NCAVPerShare() != 3.16 || nets[0].LiquidationValue() != -1.47 {
+		t.Error("Error in screened NCAV ", nets[0])
+	}
+
+	// Haircuts lower the liquidation value and not the NCAV the screen is on
+	none, _ := v.NetNetScreen(LiquidationHaircuts{})
+	if len(none) != 2 || none[0].NCAVPerShare() != nets[0].NCAVPerShare() ||
+		none[0].LiquidationValue() <= nets[0].LiquidationValue() {
+		t.Error("Error in haircut effect ", none)
+	}
+}
+
+func TestTangibleBookValue(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+
+	m := v.Measures("CSCO")
+	f := m[0].Filing()
+	eq, _ := f.TotalEquity()
+	gw, _ := f.Goodwill()
+	intan, _ := f.Intangibles()
+	sc, _ := f.ShareCount()
+	if m[0].TangibleBookValue() != round((eq-gw-intan)/sc) {
+		t.Error("Tangible book value was not the expected value", m[0].TangibleBookValue())
+	}
+	if m[0].GoodwillToEquity() != percentage(gw/eq) {
+		t.Error("Goodwill to equity was not the expected value", m[0].GoodwillToEquity())
+	}
+	if m[0].ReturnOnTangibleEquity() <= m[0].ReturnOnEquity() {
+		t.Error("Error: Return on tangible equity should be above RoE ", m[0].ReturnOnTangibleEquity())
+	}
+	if m[1].Yoy().TangibleBookValueGrowth() != yoyCalc(m[0].TangibleBookValue(), m[1].TangibleBookValue(), false) {
+		t.Error("Error in tangible book value growth ", m[1].Yoy().TangibleBookValueGrowth())
+	}
+
+	total, _ := v.DiscountedCashFlowTrend("CSCO", 3, 100, 10, 2018)
+	fcf, _ := v.DiscountedFCFTrend("CSCO", 3, 100, 10, 2018)
+	v.SetTangibleBookValue("CSCO", true)
+	tangible, err := v.DiscountedCashFlowTrend("CSCO", 3, 100, 10, 2018)
+	if err != nil || tangible >= total {
+		t.Error("Error: DCF on tangible book value should be lower ", tangible, total)
+	}
+	// The tangible book value and its growth are discounted together
+	avg := v.Averages("CSCO")
+	start, _ := v.DiscountedCashFlow("CSCO", 3, avg.AvgTangibleBookValueGrowth(), avg.AvgDividendGrowth(), 10, 2018)
+	if tangible != start {
+		t.Error("Error: Tangible DCF should grow at the tangible book value growth ", tangible, start)
+	}
+	if tfcf, err := v.DiscountedFCFTrend("CSCO", 3, 100, 10, 2018); err != nil || tfcf >= fcf {
+		t.Error("Error: FCF DCF on tangible book value should be lower ", tfcf, fcf)
+	}
+	v.SetTangibleBookValue("CSCO", false)
+	if val, _ := v.DiscountedCashFlowTrend("CSCO", 3, 100, 10, 2018); val != total {
+		t.Error("Error: DCF should be back on the total book value ", val, total)
+	}
+}
+
+func TestPiotroskiFScore(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+
+	m := v.Measures("CSCO")
+	if m[0].FScore() != nil {
+		t.Error("Error: First filing should not have an F-score")
+	}
+	for _, mea := range m[1:] {
+		fs := mea.FScore()
+		if fs == nil || fs.Score() < 0 || fs.Score() > 9 {
+			t.Error("Error in F-score ", mea.FiledOn(), fs)
+			continue
+		}
+		if !fs.PositiveReturnOnAssets() || !fs.PositiveCashFlow() {
+			t.Error("Error: CSCO has positive earnings and cash flow ", mea.FiledOn(), fs)
+		}
+	}
+	ps, _ := m[0].Filing().ShareCount()
+	cs, _ := m[1].Filing().ShareCount()
+	if m[1].FScore().NoDilution() != (cs <= ps) {
+		t.Error("Error in dilution signal ", ps, cs)
+	}
+	if !strings.Contains(m[1].String(), `"Piotroski F-Score"`) {
+		t.Error("Error: F-score should be in the measures JSON")
+	}
+}
+
+func TestAltmanZ(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+
+	m := v.Measures("CSCO")
+	for _, mea := range m {
+		z := mea.AltmanZ()
+		if z == nil || z.MarketValue() {
+			t.Error("Error in book value Altman Z ", mea.FiledOn(), z)
+			continue
+		}
+		if z.Zone() != zone(z.Z(), 2.99, 1.81) || z.ZDoublePrimeZone() != zone(z.ZDoublePrime(), 2.6, 1.1) {
+			t.Error("Error in Altman Z zones ", z)
+		}
+	}
+
+	last := m[len(m)-1]
+	eq, _ := last.Filing().TotalEquity()
+	z := newAltmanZ(last, eq*2)
+	if !z.MarketValue() || z.Z() <= last.AltmanZ().Z() || z.ZDoublePrime() != last.AltmanZ().ZDoublePrime() {
+		t.Error("Error: Market value above book should only raise Z ", z, last.AltmanZ())
+	}
+	if !strings.Contains(last.String(), `"Altman Z"`) {
+		t.Error("Error: Altman Z should be in the measures JSON")
+	}
+}
+
+func TestDuPont(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+
+	m := v.Measures("CSCO")
+	for _, mea := range m {
+		d := mea.DuPont()
+		if d == nil {
+			t.Error("Error: DuPont breakdown missing ", mea.FiledOn())
+			continue
+		}
+		if math.Abs(d.ReturnOnEquity()-mea.ReturnOnEquity()) > 1 {
+			t.Error("Error: DuPont RoE should match RoE ", d.ReturnOnEquity(), mea.ReturnOnEquity())
+		}
+	}
+
+	// The attribution adds up to the change in RoE
+	for i := 1; i < len(m); i++ {
+		y := m[i].Yoy()
+		sum := y.NetMarginContribution() + y.AssetTurnoverContribution() + y.EquityMultiplierContribution()
+		change := m[i].DuPont().ReturnOnEquity() - m[i-1].DuPont().ReturnOnEquity()
+		if math.Abs(sum-change) > 0.05 {
+			t.Error("Error in DuPont attribution ", m[i].FiledOn(), sum, change)
+		}
+	}
+}
+
+func TestReturnOnInvestedCapital(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+
+	m := v.Measures("CSCO")
+	for _, mea := range m {
+		f := mea.Filing()
+		eq, _ := f.TotalEquity()
+		ld, _ := f.LongTermDebt()
+		sd, _ := f.ShortTermDebt()
+		cash, _ := f.Cash()
+		if mea.InvestedCapital() != eq+ld+sd-cash {
+			t.Error("Error in invested capital ", mea.FiledOn(), mea.InvestedCapital())
+		}
+		oi, _ := f.OperatingIncome()
+		roic := percentage(oi * 0.75 / mea.InvestedCapital())
+		if mea.ReturnOnInvestedCapital(25) != roic {
+			t.Error("Error in RoIC ", mea.FiledOn(), mea.ReturnOnInvestedCapital(25), roic)
+		}
+		if mea.PreTaxReturnOnInvestedCapital() != percentage(oi/mea.InvestedCapital()) {
+			t.Error("Error in pre-tax RoIC ", mea.FiledOn(), mea.PreTaxReturnOnInvestedCapital())
+		}
+	}
+
+	last, past := m[len(m)-1], m[len(m)-2]
+	oi, _ := last.Filing().OperatingIncome()
+	capex, _ := last.Filing().CapitalExpenditure()
+	reinvest := percentage((math.Abs(capex) + last.WorkingCapital() - past.WorkingCapital()) / oi)
+	if last.Yoy().ReinvestmentRate() != reinvest {
+		t.Error("Error in reinvestment rate ", last.Yoy().ReinvestmentRate(), reinvest)
+	}
+	if v.Averages("CSCO").AvgReinvestmentRate() == 0 {
+		t.Error("Error: Average reinvestment rate missing")
+	}
+
+	val := v.(*valuator)
+	c, err := val.costOfCapital("CSCO", 200000000000, WACCOptions{
+		RiskFreeRate:      3,
+		EquityRiskPremium: 5,
+		Beta:              1,
+		CostOfDebt:        4,
+		TaxRate:           25,
+	})
+	if err != nil {
+		t.Error("Failed to compute cost of capital: ", err.Error())
+		return
+	}
+	if c.ReturnOnInvestedCapital() != m[len(m)-1].ReturnOnInvestedCapital(25) {
+		t.Error("Error: Spread should use the RoIC of the last filing at the WACC tax rate ", c.ReturnOnInvestedCapital())
+	}
+	if math.Abs(c.Spread()-(c.ReturnOnInvestedCapital()-c.WACC())) > 0.01 {
+		t.Error("Error in RoIC - WACC spread ", c.Spread())
+	}
+}
+
+func TestShareholderYield(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+
+	m := v.Measures("CSCO")
+	last, past := m[len(m)-1], m[len(m)-2]
+	psc, _ := past.Filing().ShareCount()
+	csc, _ := last.Filing().ShareCount()
+	y := last.Yoy()
+	if y.NetShareChange() != round((csc-psc)/psc*100) {
+		t.Error("Error in net share change ", y.NetShareChange())
+	}
+	if y.NetShareChange() < 0 && y.DilutionRate() != 0 {
+		t.Error("Error: Buyback year should have no dilution ", y.DilutionRate())
+	}
+
+	// Per share growth differs from absolute growth by the share change
+	prev, _ := past.Filing().Revenue()
+	crev, _ := last.Filing().Revenue()
+	if g := y.PerShareGrowth(BasicShares); g == nil || g.Revenue() != percentage((crev/csc-prev/psc)/(prev/psc)) {
+		t.Error("Error in revenue per share growth ", g)
+	}
+
+	pm := newPriceBasedMetricsAt(last, v.Averages("CSCO"), 50)
+	dy, err := pm.DividendYield()
+	if err != nil || dy != round(last.DividendPerShare()/50*100) {
+		t.Error("Error in dividend yield ", dy, err)
+	}
+	if pm.BuybackYield() != round((psc-csc)/csc*100) {
+		t.Error("Error in buyback yield ", pm.BuybackYield(), (psc-csc)/csc*100)
+	}
+	sy, err := pm.ShareholderYield()
+	if err != nil || math.Abs(sy-(dy+pm.BuybackYield())) > 0.01 {
+		t.Error("Error in shareholder yield ", sy, err)
+	}
+
+	// No share change from a past year without shares or a share count that
+	// was not collected, but the other growth is still computed
+	for _, err := range []error{nil, errors.New("Shares Outstanding not collected")} {
+		mea := newMeasures([]Filing{&testNoShares{past.Filing(), err}, last.Filing()})
+		if e := newYoYs(mea); e != nil {
+			t.Error("Error: YoY should not need the share count ", e)
+			continue
+		}
+		y = mea[1].Yoy()
+		if y.NetShareChange() != 0 || y.DilutionRate() != 0 {
+			t.Error("Error: Share change needs a past share count ", y.NetShareChange())
+		}
+		if y.RevenueGrowth() != last.Yoy().RevenueGrowth() {
+			t.Error("Error: Revenue growth should not need the share count ", y.RevenueGrowth())
+		}
+	}
+}
+
+// testNoShares is a filing with a share count of 0 or none collected
+type testNoShares struct {
+	Filing
+	err error
+}
+
+func (f *testNoShares) ShareCount() (float64, error) {
+	return 0, f.err
+}
+
+func TestPerShare(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+
+	m := v.Measures("CSCO")
+	for _, mea := range m {
+		basic := mea.PerShare(BasicShares)
+		weighted := mea.PerShare(WeightedShares)
+		if basic == nil || weighted == nil {
+			t.Error("Error: Per share values missing ", mea.FiledOn())
+			continue
+		}
+		if basic.BookValue() != mea.BookValue() {
+			t.Error("Error: Basic book value should match measures ", basic.BookValue(), mea.BookValue())
+		}
+		ni, _ := mea.Filing().NetIncome()
+		wa, _ := mea.Filing().WAShares()
+		if weighted.Shares() != wa || weighted.Earnings() != round(ni/wa) {
+			t.Error("Error in weighted EPS ", weighted.Earnings(), round(ni/wa))
+		}
+	}
+	if m[0].PerShare("Diluted") != nil {
+		t.Error("Error: Unknown share basis should have no values")
+	}
+
+	last := m[len(m)-1]
+	if last.Yoy().PerShareGrowth(WeightedShares) == nil {
+		t.Error("Error: Weighted per share growth missing")
+	}
+	if v.Averages("CSCO").AvgPerShareGrowth(WeightedShares) == nil {
+		t.Error("Error: Average weighted per share growth missing")
+	}
+
+	// Per share and whole company values convert back and forth
+	dcf, err := v.DiscountedFCFTrend("CSCO", 3, 100, 10)
+	if err != nil {
+		t.Error("Failed to run DCF: ", err.Error())
+		return
+	}
+	total, err := v.CompanyValue("CSCO", dcf)
+	if err != nil {
+		t.Error("Failed to get company value: ", err.Error())
+		return
+	}
+	back, err := v.ShareValue("CSCO", total, BasicShares)
+	if err != nil || math.Abs(back-dcf) > 0.01 {
+		t.Error("Error converting company value to per share ", back, dcf)
+	}
+	if _, err = v.CompanyValue("CSCO", dcf, 2000); err == nil {
+		t.Error("Error: No company value before the first filing")
+	}
+}
+
+func TestPriceRatios(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+
+	m := v.Measures("CSCO")
+	last := m[len(m)-1]
+	avg := v.Averages("CSCO")
+	pm := newPriceBasedMetricsAt(last, avg, 50)
+
+	pb, err := pm.PriceToBook()
+	eq, _ := last.Filing().TotalEquity()
+	if err != nil || pb != round(pm.MarketCapitalization()/eq) {
+		t.Error("Error in P/B ", pb, err)
+	}
+	ptbv, err := pm.PriceToTangibleBook()
+	if err != nil || ptbv < pb {
+		t.Error("Error: P/TBV should be at least P/B ", ptbv, pb, err)
+	}
+	evr, err := pm.EVToRevenue()
+	rev, _ := last.Filing().Revenue()
+	if err != nil || evr != round(pm.EnterpriseValue()/rev) {
+		t.Error("Error in EV/Revenue ", evr, err)
+	}
+	if _, err = pm.EVToOperatingIncome(); err != nil {
+		t.Error("Error in EV/Operating Income ", err)
+	}
+	ey, err := pm.EarningsYield()
+	if err != nil || math.Abs(ey-100/pm.PriceOverEarnings()) > 0.1 {
+		t.Error("Error: Earnings yield should be the inverse of P/E ", ey, pm.PriceOverEarnings())
+	}
+	if _, err = pm.FCFYield(); err != nil {
+		t.Error("Error in FCF yield ", err)
+	}
+	peg, err := pm.PEG()
+	if avg.AvgEarningsGrowth() > 0 {
+		if err != nil || math.Abs(peg-pm.PriceOverEarnings()/avg.AvgEarningsGrowth()) > 0.01 {
+			t.Error("Error in PEG ", peg, err)
+		}
+	} else if err == nil {
+		t.Error("Error: PEG should fail without earnings growth")
+	}
+
+	// No PEG without growth and no ratios at all without a price
+	if _, err = newPriceBasedMetricsAt(last, nil, 50).PEG(); err == nil {
+		t.Error("Error: PEG should fail without averages")
+	}
+	noPrice := newPriceBasedMetricsAt(last, avg, -1)
+	if _, err = noPrice.PriceToBook(); err == nil {
+		t.Error("Error: P/B should fail without a price")
+	}
+	if _, err = noPrice.ShareholderYield(); err == nil {
+		t.Error("Error: Shareholder yield should fail without a price")
+	}
+	if noPrice.AltmanZ() != nil {
+		t.Error("Error: Altman Z on the market value needs a price ", noPrice.AltmanZ())
+	}
+
+	// Missing earnings are reported as such and not as a loss
+	noIncome := newMeasures([]Filing{&testNoIncome{last.Filing()}})[0]
+	if _, err = newPriceBasedMetricsAt(noIncome, avg, 50).PEG(); err == nil || !strings.Contains(err.Error(), "not collected") {
+		t.Error("Error: PEG should fail with the missing net income ", err)
+	}
+
+	// No P/x values over a loss, negative cash flow or no revenue
+	loss := newMeasures([]Filing{&testLossFiling{last.Filing()}})[0]
+	pm = newPriceBasedMetricsAt(loss, avg, 50)
+	if pm.PriceOverEarnings() != 0 || pm.PriceOverCashFlow() != 0 || pm.PriceOverRevenue() != 0 {
+		t.Error("Error: P/x needs a positive denominator ", pm.PriceOverEarnings(), pm.PriceOverCashFlow(), pm.PriceOverRevenue())
+	}
+	if _, err = pm.PEG(); err == nil {
+		t.Error("Error: PEG should fail on a loss")
+	}
+}
+
+// testLossFiling is a filing with a loss, negative cash flow and no revenue
+type testLossFiling struct {
+	Filing
+}
+
+func (f *testLossFiling) NetIncome() (float64, error) {
+	return -1000000, nil
+}
+
+func (f *testLossFiling) OperatingCashFlow() (float64, error) {
+	return -1000000, nil
+}
+
+func (f *testLossFiling) Revenue() (float64, error) {
+	return 0, nil
+}
+
+// testNoIncome is a filing with no net income collected
+type testNoIncome struct {
+	Filing
+}
+
+func (f *testNoIncome) NetIncome() (float64, error) {
+	return 0, errors.New("Net Income not collected")
+}
+
+func TestMultiplesHistory(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+	val := v.(*valuator)
+	src, err := NewPriceSource("./testdata/prices/", CSVPriceSourceType)
+	if err != nil {
+		t.Error("Failed to create price source: ", err.Error())
+		return
+	}
+
+	m := v.Measures("CSCO")
+	last := m[len(m)-1]
+	current := newPriceBasedMetricsAt(last, v.Averages("CSCO"), 60)
+	h, err := val.multiplesHistory("CSCO", src, current)
+	if err != nil {
+		t.Error("Failed to get multiples history: ", err.Error())
+		return
+	}
+	if len(h.Dates()) != len(m) || len(h.Metrics()) != len(m) {
+		t.Error("Error: Every filing should have a close ", h.Dates())
+	}
+	// A filing on a day without a close takes the close before
+	for i, d := range h.Dates() {
+		if d == "2014-09-09" && h.Metrics()[i].Price() != 25.18 {
+			t.Error("Error in close before the filing date ", h.Metrics()[i].Price())
+		}
+	}
+
+	lo, hi, err := h.Range(MultiplePB)
+	if err != nil || lo > hi {
+		t.Error("Error in P/B range ", lo, hi, err)
+	}
+	// Today's P/B at a price above every historical close is at the top
+	pb, _ := h.Current(MultiplePB)
+	p, err := h.Percentile(MultiplePB)
+	if err != nil {
+		t.Error("Error in P/B percentile ", err.Error())
+	} else if pb > hi && p != 100 {
+		t.Error("Error: P/B above the range should be the 100th percentile ", p)
+	}
+
+	if _, err = h.Percentile("P/X"); err == nil {
+		t.Error("Error: Unknown multiple should fail")
+	}
+	if _, err = val.multiplesHistory("CSCO", src, nil); err != nil {
+		t.Error("Error: History should not need a current price ", err.Error())
+	}
+	if _, err = val.multiplesHistory("IBM", src, current); err == nil {
+		t.Error("Error: Ticker not collected should fail")
+	}
+}
+
+func TestBacktest(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+	src, err := NewPriceSource("./testdata/prices/", CSVPriceSourceType)
+	if err != nil {
+		t.Error("Failed to create price source: ", err.Error())
+		return
+	}
+
+	opts := DefaultBacktestOptions()
+	opts.Tickers = []string{"CSCO"}
+	opts.Prices = src
+	b, err := v.Backtest(opts)
+	if err != nil {
+		t.Error("Failed to backtest: ", err.Error())
+		return
+	}
+
+	// The first filing has no YoY and the last has no price a year later
+	m := v.Measures("CSCO")
+	if len(b.Observations()) != (len(m)-2)*len(scenarioModels) {
+		t.Error("Error in number of backtest observations ", len(b.Observations()))
+	}
+	for _, o := range b.Observations() {
+		// The value must not depend on filings after the observation
+		if o.Model == ModelDCFTrend {
+			val, _ := v.DiscountedCashFlowTrend("CSCO", 3, 100, 10, getYear(o.Date.String()))
+			if val != o.Value {
+				t.Error("Error: Backtest value should only use past filings ", o)
+			}
+		}
+		if o.Hit != ((o.Gap > 0) == (o.ForwardReturn > 0)) {
+			t.Error("Error in backtest hit ", o)
+		}
+	}
+
+	var count int
+	for _, bkt := range b.Buckets() {
+		count += bkt.Count
+	}
+	if len(b.Buckets()) != len(opts.GapBuckets)+1 || count != len(b.Observations()) {
+		t.Error("Error: Every observation should be in one bucket ", b.Buckets())
+	}
+	if b.HitRate() < 0 || b.HitRate() > 100 {
+		t.Error("Error in hit rate ", b.HitRate())
+	}
+	for _, model := range scenarioModels {
+		if _, err = b.ModelAccuracy(model); err != nil {
+			t.Error("Error in model accuracy ", err.Error())
+		}
+	}
+	if !strings.Contains(b.String(), "Hit Rate") {
+		t.Error("Error in backtest output")
+	}
+
+	opts.Tickers = []string{"IBM"}
+	if _, err = v.Backtest(opts); err == nil {
+		t.Error("Error: Backtest of a ticker not collected should fail")
+	}
+}
+
+func TestAsOf(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	err = v.Collect("CSCO")
+	if err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+	src, err := NewPriceSource("./testdata/prices/", CSVPriceSourceType)
+	if err != nil {
+		t.Error("Failed to create price source: ", err.Error())
+		return
+	}
+
+	date := time.Time(getDate("2015-09-10"))
+	view, err := v.AsOf(date, src)
+	if err != nil {
+		t.Error("Failed to create view: ", err.Error())
+		return
+	}
+	m := view.Measures("CSCO")
+	if len(m) != 4 {
+		t.Error("Error: View should only have the filings up to the date ", len(m))
+	}
+	for _, mea := range m {
+		if mea.Filing().FiledOn().After(date) {
+			t.Error("Error: Filing after the date in view ", mea.FiledOn())
+		}
+	}
+	if view.LastFiling("CSCO").FiledOn().After(date) {
+		t.Error("Error: Last filing should be before the date")
+	}
+
+	// Values in the view match the end year on the full data
+	dcf, _ := v.DiscountedCashFlowTrend("CSCO", 3, 100, 10, 2015)
+	vdcf, err := view.DiscountedCashFlowTrend("CSCO", 3, 100, 10)
+	if err != nil || dcf != vdcf {
+		t.Error("Error: View DCF should match the end year DCF ", vdcf, dcf)
+	}
+	if view.Averages("CSCO").AvgRevenueGrowth() == v.Averages("CSCO").AvgRevenueGrowth() {
+		t.Error("Error: View averages should not include later filings")
+	}
+	if view.PriceMetrics("CSCO").Price() != 25.66 {
+		t.Error("Error: View price should be the close on the date ", view.PriceMetrics("CSCO").Price())
+	}
+
+	if err = view.Collect("IBM"); err == nil {
+		t.Error("Error: View should not collect")
+	}
+	if err = view.Write(); err == nil {
+		t.Error("Error: View should not write")
+	}
+	if !strings.Contains(view.String(), "2015-09-10") {
+		t.Error("Error in view output")
+	}
+
+	// Nothing is known before the first filing
+	view, _ = v.AsOf(time.Time(getDate("2010-01-01")), nil)
+	if len(view.Measures("CSCO")) != 0 {
+		t.Error("Error: View before any filing should be empty")
+	}
+}
+
+func TestFiscalYear(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	for _, ticker := range []string{"CSCO", "IBM", "TGT"} {
+		if err = v.Collect(ticker); err != nil {
+			t.Error("Failed to create a valuator: ", err.Error())
+			return
+		}
+	}
+
+	// Fiscal years ending in July, December and January
+	ends := map[string]string{"CSCO": "-07-31", "IBM": "-12-31", "TGT": "-01-31"}
+	for ticker, end := range ends {
+		for _, m := range v.Measures(ticker) {
+			filed := getYear(m.FiledOn())
+			fy := filed
+			if ticker != "CSCO" {
+				fy = filed - 1
+			}
+			if m.FiscalYear() != fy {
+				t.Error("Error in fiscal year ", ticker, m.FiledOn(), m.FiscalYear())
+			}
+			if !strings.HasSuffix(m.PeriodEnd(), end) {
+				t.Error("Error in period end ", ticker, m.PeriodEnd())
+			}
+		}
+	}
+
+	// The end year is the fiscal year and not the year of filing
+	ibm := v.Measures("IBM")
+	if l := createMeasuresList(ibm, 2017); l[len(l)-1].FiledOn() != "2018-02-27" {
+		t.Error("Error: Fiscal 2017 of IBM is filed in 2018 ", l[len(l)-1].FiledOn())
+	}
+
+	// No YoY across a fiscal year that is not collected
+	var fs []Filing
+	for i, f := range v.Filings("CSCO") {
+		if i != 2 {
+			fs = append(fs, f)
+		}
+	}
+	mea := newMeasures(fs)
+	newYoYs(mea)
+	if !reflect.ValueOf(mea[2].Yoy()).IsNil() || reflect.ValueOf(mea[3].Yoy()).IsNil() {
+		t.Error("Error: YoY should only pair consecutive fiscal years")
+	}
+}
+
+// testPeriodFiling is a filing that reports its fiscal period
+type testPeriodFiling struct {
+	ReportedFiling
+	end  time.Time
+	year int
+}
+
+func (f *testPeriodFiling) DocumentPeriodEndDate() (time.Time, error) {
+	if f.end.IsZero() {
+		return f.end, errors.New("Document period end date not collected")
+	}
+	return f.end, nil
+}
+
+func (f *testPeriodFiling) DocumentFiscalYearFocus() (int, error) {
+	if f.year == 0 {
+		return 0, errors.New("Document fiscal year focus not collected")
+	}
+	return f.year, nil
+}
+
+func TestReportedFiscalPeriod(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	if err = v.Collect("CSCO"); err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+	last := v.Filings("CSCO")[len(v.Filings("CSCO"))-1]
+
+	// A March year end filed late is not where the filing date puts it
+	end := time.Date(last.FiledOn().Year(), time.March, 31, 0, 0, 0, 0, time.UTC)
+	f := newFiscalFiling(&testPeriodFiling{last, end, end.Year() - 1})
+	if !f.PeriodEnd().Equal(end) || f.FiscalYear() != end.Year()-1 {
+		t.Error("Error: Reported fiscal period should be used ", f.PeriodEnd(), f.FiscalYear())
+	}
+	if f = newFiscalFiling(&testPeriodFiling{last, end, 0}); f.FiscalYear() != end.Year()-1 {
+		t.Error("Error: Fiscal year should be named by the period end ", f.FiscalYear())
+	}
+
+	// The estimate is only used when the period end is not reported
+	f = newFiscalFiling(&testPeriodFiling{last, time.Time{}, 1999})
+	if f.FiscalYear() != last.FiscalYear() || !f.PeriodEnd().Equal(last.PeriodEnd()) {
+		t.Error("Error: Fiscal period should be estimated without a period end ", f.PeriodEnd(), f.FiscalYear())
+	}
+}
+
+// testAmendment restates the revenue of the fiscal period it reports
+type testAmendment struct {
+	testPeriodFiling
+	filed   time.Time
+	revenue float64
+}
+
+func (a *testAmendment) FiledOn() time.Time {
+	return a.filed
+}
+
+func (a *testAmendment) Revenue() (float64, error) {
+	return a.revenue, nil
+}
+
+func TestAmendedFiling(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	if err = v.Collect("CSCO"); err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+	fs := v.Filings("CSCO")
+	last := fs[len(fs)-1]
+	rev, _ := last.Revenue()
+	filed := last.FiledOn().AddDate(0, 3, 0)
+	period := testPeriodFiling{last, last.PeriodEnd(), last.FiscalYear()}
+	a := newAmendment(fs, &testAmendment{period, filed, rev * 1.1})
+	if a == nil || a.FiscalYear() != last.FiscalYear() || a.PeriodEnd() != last.PeriodEnd() {
+		t.Error("Error: Amendment should be in the fiscal year of the filing it amends")
+		return
+	}
+	if newAmendment(fs, &testAmendment{period, last.FiledOn().AddDate(0, 0, -1), rev}) != nil {
+		t.Error("Error: Amendment filed before the filing it amends should be dropped")
+	}
+	if newAmendment(fs, &testAmendment{testPeriodFiling{last, last.PeriodEnd(), 1990}, filed, rev}) != nil {
+		t.Error("Error: Amendment of no known fiscal year should be dropped")
+	}
+	if newAmendment(fs, &testAmendment{testPeriodFiling{last, time.Time{}, 0}, filed, rev}) != nil {
+		t.Error("Error: Amendment without a period should be dropped")
+	}
+
+	rf := reconcileFilings(append(fs, a))
+	if len(rf) != len(fs) {
+		t.Error("Error: Amendment should be merged with the filing ", len(rf))
+		return
+	}
+	mea := newMeasures(rf)
+	m := mea[len(mea)-1]
+	if r, _ := m.Filing().Revenue(); r != rev*1.1 {
+		t.Error("Error: Amended revenue should be used ", r)
+	}
+	ni, _ := last.NetIncome()
+	if ani, _ := m.Filing().NetIncome(); ani != ni {
+		t.Error("Error: Values not amended should come from the original ", ani)
+	}
+	if r, _ := m.AsFiled().Revenue(); r != rev {
+		t.Error("Error: As filed revenue should be the original ", r)
+	}
+	if m.FiledOn() != Timestamp(last.FiledOn()).String() {
+		t.Error("Error: Amended filing should be dated as the original ", m.FiledOn())
+	}
+	rs := m.Restatements()
+	if len(rs) != 1 || rs[0].Field != "Revenue" || rs[0].Change != 10 ||
+		rs[0].Original != rev || time.Time(rs[0].On) != filed {
+		t.Error("Error in restatements ", rs)
+	}
+	if len(mea[0].Restatements()) != 0 || !strings.Contains(m.String(), "Restatements") {
+		t.Error("Error: Only the amended filing should have restatements")
+	}
+
+	// Point in time values are the ones filed by the date
+	if f := filingAsOf(m.Filing(), filed.AddDate(0, 0, -1)); f == nil {
+		t.Error("Error: Original should be known before the amendment")
+	} else if r, _ := f.Revenue(); r != rev {
+		t.Error("Error: Amendment known before it was filed ", r)
+	}
+	if f := filingAsOf(m.Filing(), filed); f == nil {
+		t.Error("Error: Amendment should be known on its filing date")
+	} else if r, _ := f.Revenue(); r != rev*1.1 {
+		t.Error("Error: Amendment should be used once filed ", r)
+	}
+	if filingAsOf(m.Filing(), last.FiledOn().AddDate(0, 0, -1)) != nil {
+		t.Error("Error: Filing known before it was filed")
+	}
+}
+
+func TestAmendedFolder(t *testing.T) {
+	db, err := NewDatabase("./testdata/filings/", FileDatabaseType)
+	if err != nil {
+		t.Error("Failed to create database: ", err.Error())
+		return
+	}
+	v, _ := NewValuator(db)
+	if err = v.Collect("ACME"); err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+
+	// The 10-K/A of fiscal 2017 is filed after the 10-K of fiscal 2018 and
+	// the one of fiscal 2015 amends a year that was not collected
+	m := v.Measures("ACME")
+	if len(m) != 2 || m[0].FiscalYear() != 2017 || m[0].PeriodEnd() != "2017-07-29" {
+		t.Error("Error: Fiscal periods should be as reported ", len(m))
+		return
+	}
+	if r, _ := m[0].Filing().Revenue(); r != 50000000000 {
+		t.Error("Error: Amended revenue should be used ", r)
+	}
+	if r, _ := m[0].AsFiled().Revenue(); r != 48005000000 {
+		t.Error("Error: As filed revenue should be the original ", r)
+	}
+	if rs := m[0].Restatements(); len(rs) != 1 || rs[0].On.String() != "2018-10-15" {
+		t.Error("Error in restatements ", rs)
+	}
+	if len(m[1].Restatements()) != 0 {
+		t.Error("Error: Fiscal 2018 was not amended ", m[1].Restatements())
+	}
+
+	// Multiples on a filing date are of the values filed by then
+	src, err := NewPriceSource("./testdata/prices/", CSVPriceSourceType)
+	if err != nil {
+		t.Error("Failed to create price source: ", err.Error())
+		return
+	}
+	h, err := v.MultiplesHistory("ACME", src)
+	if err != nil {
+		t.Error("Error in multiples history ", err)
+		return
+	}
+	pm := h.Metrics()[0]
+	if pm.PriceOverRevenue() != round(pm.MarketCapitalization()/48005000000) {
+		t.Error("Error: Amendment used before it was filed ", pm.PriceOverRevenue())
+	}
+}
+
+// testForeignFiling is a filing that reports its currency
+type testForeignFiling struct {
+	ReportedFiling
+}
+
+func (f *testForeignFiling) Currency() string {
+	return "jpy"
+}
+
+func TestReportingCurrency(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	if err = v.Collect("CSCO"); err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+	for _, m := range v.Measures("CSCO") {
+		if m.Currency() != "USD" || m.Filing().Currency() != "USD" {
+			t.Error("Error: Filings should default to USD ", m.Currency())
+		}
+	}
+	fs := v.Filings("CSCO")
+	if f := newFiscalFiling(&testForeignFiling{fs[0]}); f.Currency() != "JPY" {
+		t.Error("Error: Currency reported by the filing should be used ", f.Currency())
+	}
+
+	// No YoY across a change of reporting currency
+	mea := newMeasures(append(fs[:len(fs)-1:len(fs)-1], &currencyFiling{Filing: fs[len(fs)-1], currency: "EUR"}))
+	newYoYs(mea)
+	if !reflect.ValueOf(mea[len(mea)-1].Yoy()).IsNil() || reflect.ValueOf(mea[len(mea)-2].Yoy()).IsNil() {
+		t.Error("Error: YoY should not pair filings in different currencies")
+	}
+
+	v, _ = NewValuator(testFileDB)
+	if err = v.SetReportingCurrency("CSCO", "EURO"); err == nil {
+		t.Error("Error: Currency code should be validated")
+	}
+	if err = v.SetReportingCurrency("CSCO", "eur"); err != nil {
+		t.Error("Failed to set the currency: ", err.Error())
+		return
+	}
+	if err = v.Collect("CSCO"); err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+	for _, f := range v.Filings("CSCO") {
+		if f.Currency() != "USD" {
+			t.Error("Error: 10-K filings in USD should stay in USD ", f.Currency())
+		}
+	}
+}
+
+func TestForeignFiler(t *testing.T) {
+	db, err := NewDatabase("./testdata/filings/", FileDatabaseType)
+	if err != nil {
+		t.Error("Failed to create database: ", err.Error())
+		return
+	}
+	v, _ := NewValuator(db)
+	if err = v.Collect("FRGN"); err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+
+	// Fiscal 2015 and 2016 are filed on 20-F and fiscal 2017 on 10-K. The
+	// 20-F does not report its currency so it is unknown
+	m := v.Measures("FRGN")
+	if len(m) != 3 {
+		t.Error("Error: Every annual report type should be collected ", len(m))
+		return
+	}
+	for i, cur := range []string{"", "", "USD"} {
+		if m[i].FiscalYear() != 2015+i || m[i].Currency() != cur {
+			t.Error("Error in fiscal year or currency ", m[i].FiscalYear(), m[i].Currency())
+		}
+	}
+	if reflect.ValueOf(m[1].Yoy()).IsNil() || !reflect.ValueOf(m[2].Yoy()).IsNil() {
+		t.Error("Error: YoY should only pair the years in the same currency")
+	}
+	pm := newPriceBasedMetricsAt(m[1], v.Averages("FRGN"), 50)
+	if _, err = pm.PriceToBook(); err == nil || !strings.Contains(err.Error(), "unknown currency") {
+		t.Error("Error: Price ratios need the currency of the filings ", err)
+	}
+	rates, err := NewFXRateSource("./testdata/fx/rates.csv", CSVFXRateSourceType)
+	if err != nil {
+		t.Error("Failed to create FX rate source: ", err.Error())
+		return
+	}
+	if view, err := v.ReportIn("USD", rates); err != nil || len(view.Measures("FRGN")) != 0 {
+		t.Error("Error: Filings in an unknown currency cannot be converted ", err)
+	}
+
+	// The currency set for the ticker is used for the 20-F filings and the
+	// 10-K stays in USD
+	v, _ = NewValuator(db)
+	v.SetReportingCurrency("FRGN", "EUR")
+	if err = v.Collect("FRGN"); err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+	for i, cur := range []string{"EUR", "EUR", "USD"} {
+		if f := v.Filings("FRGN")[i]; f.Currency() != cur {
+			t.Error("Error: Only the unknown currencies should be set ", f.FiscalYear(), f.Currency())
+		}
+	}
+	if !strings.Contains(v.Measures("FRGN")[0].String(), `"Currency": "EUR"`) {
+		t.Error("Error: Measures output should have the currency")
+	}
+	if reflect.ValueOf(v.Averages("FRGN")).IsNil() {
+		t.Error("Error: Averages should be computed in the reporting currency")
+	}
+}
+
+func TestADRRatio(t *testing.T) {
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	if err = v.SetADRRatio("CSCO", 0); err == nil {
+		t.Error("Error: ADR ratio should be positive")
+	}
+	if err = v.SetADRRatio("CSCO", 4); err != nil {
+		t.Error("Failed to set the ADR ratio: ", err.Error())
+		return
+	}
+	if err = v.Collect("CSCO"); err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+	src, err := NewPriceSource("./testdata/prices/", CSVPriceSourceType)
+	if err != nil {
+		t.Error("Failed to create price source: ", err.Error())
+		return
+	}
+	closes, _ := src.DailyCloses("CSCO")
+
+	// Prices are of an ordinary share, a quarter of the ADR
+	date := time.Time(getDate("2018-09-06"))
+	quote, _ := closeOn(closes, date)
+	view, err := v.AsOf(date, src)
+	if err != nil {
+		t.Error("Error creating a view ", err)
+		return
+	}
+	pm := view.(*asOfView).Valuations["CSCO"].Pbm
+	sc, _ := view.Filings("CSCO")[len(view.Filings("CSCO"))-1].ShareCount()
+	if pm.Price() != quote/4 || pm.MarketCapitalization() != round(sc*quote/4) {
+		t.Error("Error: Price should be over the ADR ratio ", pm.Price(), quote)
+	}
+	h, err := v.MultiplesHistory("CSCO", src)
+	if err != nil {
+		t.Error("Error in multiples history ", err)
+		return
+	}
+	last := h.Metrics()[len(h.Metrics())-1]
+	if last.Price() != quote/4 {
+		t.Error("Error: Multiples should be per ordinary share ", last.Price())
+	}
+}
+
+func TestPriceCurrency(t *testing.T) {
+	db, err := NewDatabase("./testdata/filings/", FileDatabaseType)
+	if err != nil {
+		t.Error("Failed to create database: ", err.Error())
+		return
+	}
+	v, _ := NewValuator(db)
+	v.SetReportingCurrency("FRGN", "EUR")
+	if err = v.Collect("FRGN"); err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+
+	// Fiscal 2016 in EUR against a price in USD
+	vals := v.(*valuator).Valuations["FRGN"]
+	vals.FiledData = vals.FiledData[:2]
+	vals.Pbm = newPriceBasedMetricsAt(vals.FiledData[1], vals.Avgs, 50)
+	if _, err = v.PriceRange("FRGN", DefaultPriceRangeOptions()); err == nil || !strings.Contains(err.Error(), "ReportIn") {
+		t.Error("Error: Price range should not value EUR filings at a USD price ", err)
+	}
+	if _, err = v.ImpliedGrowth("FRGN", 3, 10); err == nil {
+		t.Error("Error: Implied growth should not mix currencies")
+	}
+	if _, err = v.NetCurrentAssetValue("FRGN", DefaultLiquidationHaircuts()); err == nil {
+		t.Error("Error: NCAV discount should not mix currencies")
+	}
+
+	src, err := NewPriceSource("./testdata/prices/", CSVPriceSourceType)
+	if err != nil {
+		t.Error("Failed to create price source: ", err.Error())
+		return
+	}
+	opts := DefaultBacktestOptions()
+	opts.Tickers = []string{"FRGN"}
+	opts.Prices = src
+	if _, err = v.Backtest(opts); err == nil || !strings.Contains(err.Error(), "ReportIn") {
+		t.Error("Error: Backtest should not value EUR filings at USD prices ", err)
+	}
+}
+
+// testScaledFiling reports its values in thousands
+type testScaledFiling struct {
+	ReportedFiling
+}
+
+func (f *testScaledFiling) Scale() float64 {
+	return 1000
+}
+
+func TestReportIn(t *testing.T) {
+	if _, err := NewFXRateSource("./testdata/fx/none.csv", CSVFXRateSourceType); err == nil {
+		t.Error("Error: FX rate source should need the file")
+	}
+	src, err := NewFXRateSource("./testdata/fx/rates.csv", CSVFXRateSourceType)
+	if err != nil {
+		t.Error("Failed to create FX rate source: ", err.Error())
+		return
+	}
+	date := time.Time(getDate("2017-07-31"))
+	rates := []struct {
+		from, to string
+		date     time.Time
+		rate     float64
+	}{
+		{"EUR", "USD", date, 1.1842},
+		{"EUR", "USD", date.AddDate(0, 0, 3), 1.1842},
+		{"USD", "EUR", date, 1 / 1.1842},
+		{"EUR", "JPY", time.Time(getDate("2018-07-31")), 1.1691 * 111.86},
+		{"EUR", "EUR", date, 1},
+	}
+	for _, r := range rates {
+		if rate, err := src.Rate(r.from, r.to, r.date); err != nil || math.Abs(rate-r.rate) > 1e-9 {
+			t.Error("Error in FX rate ", r.from, r.to, rate, err)
+		}
+	}
+	if _, err = src.Rate("EUR", "USD", date.AddDate(0, 0, -10)); err == nil {
+		t.Error("Error: No rate should be used more than a week old")
+	}
+	if _, err = src.Rate("GBP", "USD", date); err == nil {
+		t.Error("Error: No rate for a currency not in the source")
+	}
+
+	v, err := NewValuator(testFileDB)
+	if err != nil {
+		t.Error("Failed to create valuator: ", err.Error())
+		return
+	}
+	if err = v.Collect("CSCO"); err != nil {
+		t.Error("Failed to create a valuator: ", err.Error())
+		return
+	}
+
+	// CSCO in EUR stands in for a foreign filer
+	vals := v.(*valuator).Valuations["CSCO"]
+	var eur []Filing
+	for _, f := range v.Filings("CSCO") {
+		eur = append(eur, &currencyFiling{Filing: f, currency: "EUR"})
+	}
+	vals.FiledData = newMeasures(eur)
+	newYoYs(vals.FiledData)
+	vals.Avgs, _ = newAverages(vals.FiledData)
+	fs := v.Filings("CSCO")
+	scaled := newFiscalFiling(&testScaledFiling{fs[0]})
+	rev, _ := fs[0].Revenue()
+	sc, _ := fs[0].ShareCount()
+	if r, _ := scaled.Revenue(); r != rev*1000 {
+		t.Error("Error: Values in thousands should be in units ", r)
+	}
+	if s, _ := scaled.ShareCount(); s != sc {
+		t.Error("Error: Share count should not be scaled ", s)
+	}
+
+	// A price in USD does not mix with filings in EUR
+	mea := v.Measures("CSCO")
+	last := mea[len(mea)-1]
+	vals.Pbm = newPriceBasedMetricsAt(last, v.Averages("CSCO"), 30)
+	vals.Date = getDate("2018-09-28")
+	if _, err = vals.Pbm.PriceToBook(); err == nil || vals.Pbm.EnterpriseValue() != 0 ||
+		vals.Pbm.MarketCapitalization() == 0 || vals.Pbm.Currency() != "USD" {
+		t.Error("Error: Price metrics should not mix currencies ", vals.Pbm)
+	}
+
+	// Filings converted at the period end rate
+	view, err := v.ReportIn("usd", src)
+	if err != nil {
+		t.Error("Failed to create view: ", err.Error())
+		return
+	}
+	vmea := view.Measures("CSCO")
+	if len(vmea) != len(mea) {
+		t.Error("Error: View should have all the filings ", len(vmea))
+		return
+	}
+	vlast := vmea[len(vmea)-1]
+	rev, _ = last.Filing().Revenue()
+	sc, _ = last.Filing().ShareCount()
+	if r, _ := vlast.Filing().Revenue(); vlast.Currency() != "USD" || math.Abs(r-rev*1.1691) > 1e-3 {
+		t.Error("Error: Revenue should be converted at the period end rate ", r, vlast.Currency())
+	}
+	if s, _ := vlast.Filing().ShareCount(); s != sc {
+		t.Error("Error: Share count should not be converted ", s)
+	}
+	if math.Abs(vlast.BookValue()-last.BookValue()*1.1691) > 0.02 {
+		t.Error("Error: Book value should be converted ", vlast.BookValue())
+	}
+	pm := view.PriceMetrics("CSCO")
+	if _, err = pm.PriceToBook(); err != nil || pm.Price() != 30 || pm.Currency() != "USD" {
+		t.Error("Error: Price metrics should be in one currency ", pm)
+	}
+	if err = view.Collect("IBM"); err == nil || view.Write() == nil {
+		t.Error("Error: View should not collect or write")
+	}
+	if !strings.Contains(view.String(), `"Reported In": "USD"`) {
+		t.Error("Error in view output")
+	}
+
+	// Price converted at the rate on the date it was recorded
+	view, _ = v.ReportIn("EUR", src)
+	if pm = view.PriceMetrics("CSCO"); pm.Price() != round(30/1.1604) || pm.Currency() != "EUR" {
+		t.Error("Error: Price should be converted ", pm.Price())
+	}
+	if _, err = pm.PriceToBook(); err != nil {
+		t.Error("Error: Price metrics should be available in one currency ", err)
+	}
+
+	if _, err = v.ReportIn("EURO", src); err == nil {
+		t.Error("Error: Currency code should be validated")
+	}
+	if _, err = v.ReportIn("USD", nil); err == nil {
+		t.Error("Error: View needs an FX rate source")
+	}
+	if view, _ = v.ReportIn("GBP", src); len(view.Measures("CSCO")) != 0 {
+		t.Error("Error: Tickers without rates should be left out")
+	}
+}
@@ -0,0 +1,144 @@
+package valuator
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+)
+
+// WACCOptions are the market assumptions used to compute the cost of capital
+type WACCOptions struct {
+	// RiskFreeRate in %, ex: the treasury yield
+	RiskFreeRate float64
+	// EquityRiskPremium in % of the market over the risk free rate
+	EquityRiskPremium float64
+	// Beta of the stock. Estimated from the price history when 0
+	Beta float64
+	// Prices is the source of the price histories to estimate beta from
+	Prices PriceSource
+	// Benchmark is the ticker of the market index to estimate beta against
+	Benchmark string
+	// CostOfDebt is the pre-tax interest rate in % on the debt
+	CostOfDebt float64
+	// TaxRate in % to get the after-tax cost of debt
+	TaxRate float64
+}
+
+// CostOfCapital provides an interface to the CAPM and WACC computations
+// WACC can be used as the discount rate (dr) of every valuation method
+type CostOfCapital interface {
+	Beta() float64
+	CostOfEquity() float64
+	CostOfDebt() float64
+	EquityWeight() float64
+	DebtWeight() float64
+	WACC() float64
+	// ReturnOnInvestedCapital is the RoIC (%) on the last filing after the
+	// TaxRate of the options
+	ReturnOnInvestedCapital() float64
+	// Spread is RoIC - WACC. A positive spread is when growth creates value
+	Spread() float64
+	String() string
+}
+
+type costOfCapital struct {
+	B    float64 `json:"Beta"`
+	Ke   float64 `json:"Cost of Equity (%)"`
+	Kd   float64 `json:"After-tax Cost of Debt (%)"`
+	We   float64 `json:"Equity Weight (%)"`
+	Wd   float64 `json:"Debt Weight (%)"`
+	Wacc float64 `json:"WACC (%)"`
+	RoIC float64 `json:"Return on Invested Capital (%)"`
+	Sprd float64 `json:"RoIC - WACC (%)"`
+}
+
+func (c costOfCapital) String() string {
+	data, err := json.MarshalIndent(c, "", "    ")
+	if err != nil {
+		log.Fatal("Error marshaling cost of capital data: ", err)
+	}
+	return string(data)
+}
+
+func (v *valuator) CostOfCapital(ticker string, opts WACCOptions) (CostOfCapital, error) {
+	vals, ok := v.Valuations[ticker]
+	if !ok {
+		return nil, errors.New("Valuator has not be told to collect data on " + ticker)
+	}
+	if vals.Pbm == nil || vals.Pbm.MarketCapitalization() <= 0 {
+		return nil, errors.New("No market capitalization available for " + ticker)
+	}
+	return v.costOfCapital(ticker, vals.Pbm.MarketCapitalization(), opts)
+}
+
+func (v *valuator) costOfCapital(ticker string, marketCap float64, opts WACCOptions) (CostOfCapital, error) {
+	vals, ok := v.Valuations[ticker]
+	if !ok {
+		return nil, errors.New("Valuator has not be told to collect data on " + ticker)
+	}
+
+	b := opts.Beta
+	if b == 0 {
+		if opts.Benchmark == "" {
+			return nil, errors.New("Need a beta or a benchmark to estimate it against")
+		}
+		hist, err := v.PriceHistoryMetrics(ticker, opts.Benchmark, opts.Prices)
+		if err != nil {
+			return nil, err
+		}
+		b = hist.Beta()
+	}
+
+	// Debt as of the last filing. Ignore errors as debt could be 0
+	last := vals.FiledData[len(vals.FiledData)-1]
+	f := last.Filing()
+	ld, _ := f.LongTermDebt()
+	sd, _ := f.ShortTermDebt()
+	debt := ld + sd
+
+	c := &costOfCapital{
+		B:  b,
+		Ke: round(opts.RiskFreeRate + b*opts.EquityRiskPremium),
+		Kd: round(opts.CostOfDebt * (1 - opts.TaxRate/100)),
+	}
+	we := marketCap / (marketCap + debt)
+	c.We = round(we * 100)
+	c.Wd = round((1 - we) * 100)
+	c.Wacc = round(we*c.Ke + (1-we)*c.Kd)
+	// The same tax rate as the cost of debt so the spread is like for like
+	c.RoIC = last.ReturnOnInvestedCapital(opts.TaxRate)
+	c.Sprd = round(c.RoIC - c.Wacc)
+	return c, nil
+}
+
+func (c *costOfCapital) Beta() float64 {
+	return c.B
+}
+
+func (c *costOfCapital) CostOfEquity() float64 {
+	return c.Ke
+}
+
+func (c *costOfCapital) CostOfDebt() float64 {
+	return c.Kd
+}
+
+func (c *costOfCapital) EquityWeight() float64 {
+	return c.We
+}
+
+func (c *costOfCapital) DebtWeight() float64 {
+	return c.Wd
+}
+
+func (c *costOfCapital) WACC() float64 {
+	return c.Wacc
+}
+
+func (c *costOfCapital) ReturnOnInvestedCapital() float64 {
+	return c.RoIC
+}
+
+func (c *costOfCapital) Spread() float64 {
+	return c.Sprd
+}
@@ -3,6 +3,7 @@ package valuator
 import (
 	"encoding/json"
 	"log"
+	"math"
 )
 
 // Yoy is an interface to get year-over-year information from a sequence of filings
@@ -16,6 +17,29 @@ type Yoy interface {
 	CashFlowGrowth() float64
 	DividendGrowth() float64
 	BookValueGrowth() float64
+	OwnerEarningsGrowth() float64
+	TangibleBookValueGrowth() float64
+	ReturnOnTangibleEquityGrowth() float64
+	GoodwillToEquityGrowth() float64
+	ReturnOnInvestedCapitalGrowth() float64
+	// IncrementalReturnOnInvestedCapital is the change in operating income
+	// over the change in invested capital in %, before tax
+	IncrementalReturnOnInvestedCapital() float64
+	// ReinvestmentRate is the % of the operating income, before tax, put back
+	// as capex and working capital
+	ReinvestmentRate() float64
+	// NetShareChange is the % change in share count. Negative for buybacks
+	NetShareChange() float64
+	// DilutionRate is the net share change when shares were issued, else 0
+	DilutionRate() float64
+	// Past is the measures of the year compared with
+	Past() Measures
+	// PerShareGrowth is the growth (%) of each per share value on the basis
+	PerShareGrowth(basis ShareBasis) PerShareGrowth
+	// Change in RoE (% points) attributed to each DuPont driver
+	NetMarginContribution() float64
+	AssetTurnoverContribution() float64
+	EquityMultiplierContribution() float64
 }
 
 type yoy struct {
@@ -28,6 +52,22 @@ type yoy struct {
 	Cf        float64 `json:"Cash Flow Growth"`
 	Div       float64 `json:"Dividend Growth"`
 	Bv        float64 `json:"Book Value Growth"`
+	Oe        float64 `json:"Owner Earnings Growth"`
+	Tbv       float64 `json:"Tangible Book Value Growth"`
+	RoTE      float64 `json:"Return on Tangible Equity Growth"`
+	GwToEq    float64 `json:"Goodwill to Equity Growth"`
+	RoIC      float64 `json:"Return on Invested Capital Growth"`
+	IncRoIC   float64 `json:"Incremental Return on Invested Capital (%)"`
+	Reinvest  float64 `json:"Reinvestment Rate (%)"`
+	Shares    float64 `json:"Net Share Change (%)"`
+	Dilution  float64 `json:"Dilution Rate (%)"`
+	RoeNm     float64 `json:"RoE Change From Net Margin"`
+	RoeAt     float64 `json:"RoE Change From Asset Turnover"`
+	RoeEm     float64 `json:"RoE Change From Equity Multiplier"`
+
+	// Per share growth (%) on each basis
+	PsGrowth perShareGrowths `json:"Per Share Growth"`
+	past     Measures
 }
 
 func (y yoy) String() string {
@@ -39,9 +79,18 @@ func (y yoy) String() string {
 }
 
 func newYoYs(mea []Measures) error {
-	// Calculate YoY
+	// Calculate YoY between consecutive fiscal years
 	if len(mea) > 1 {
 		for i := 1; i < len(mea); i++ {
+			if mea[i].FiscalYear() != mea[i-1].FiscalYear()+1 {
+				log.Println("No YoY for fiscal year ", mea[i].FiscalYear(), " as the year before is not collected")
+				continue
+			}
+			// Growth across a change of reporting currency is mostly FX
+			if mea[i].Currency() != mea[i-1].Currency() {
+				log.Println("No YoY for fiscal year ", mea[i].FiscalYear(), " as the reporting currency changed")
+				continue
+			}
 			err := mea[i].NewYoy(mea[i-1])
 			if err != nil {
 				return err
@@ -54,6 +103,7 @@ func newYoYs(mea []Measures) error {
 func newYoy(pastMeasure Measures, currentMeasure Measures) (*yoy, error) {
 
 	ret := new(yoy)
+	ret.past = pastMeasure
 	past := pastMeasure.Filing()
 	current := currentMeasure.Filing()
 
@@ -125,6 +175,59 @@ func newYoy(pastMeasure Measures, currentMeasure Measures) (*yoy, error) {
 	c = currentMeasure.BookValue()
 	ret.Bv = yoyCalc(p, c, false)
 
+	p = pastMeasure.OwnerEarnings()
+	c = currentMeasure.OwnerEarnings()
+	ret.Oe = yoyCalc(p, c, true)
+
+	p = pastMeasure.TangibleBookValue()
+	c = currentMeasure.TangibleBookValue()
+	ret.Tbv = yoyCalc(p, c, false)
+
+	p = pastMeasure.ReturnOnTangibleEquity()
+	c = currentMeasure.ReturnOnTangibleEquity()
+	ret.RoTE = yoyCalc(p, c, true)
+
+	p = pastMeasure.GoodwillToEquity()
+	c = currentMeasure.GoodwillToEquity()
+	ret.GwToEq = yoyCalc(p, c, true)
+
+	// A flat tax rate does not change the % growth of RoIC
+	p = pastMeasure.PreTaxReturnOnInvestedCapital()
+	c = currentMeasure.PreTaxReturnOnInvestedCapital()
+	ret.RoIC = yoyCalc(p, c, true)
+
+	//Capital efficiency. Ignore errors as operating income may not be known
+	poi, _ := past.OperatingIncome()
+	coi, _ := current.OperatingIncome()
+	if dic := currentMeasure.InvestedCapital() - pastMeasure.InvestedCapital(); dic != 0 {
+		ret.IncRoIC = percentage((coi - poi) / dic)
+	}
+	if coi > 0 {
+		capex, _ := current.CapitalExpenditure()
+		wc := currentMeasure.WorkingCapital() - pastMeasure.WorkingCapital()
+		ret.Reinvest = percentage((math.Abs(capex) + wc) / coi)
+	}
+
+	//Share count. Per share growth shows if growth reaches the shareholders.
+	//Ignore errors as the share count may not be known
+	psc, perr := past.ShareCount()
+	csc, cerr := current.ShareCount()
+	// Share changes are small so they are kept to 2 decimals
+	if perr == nil && cerr == nil && psc > 0 {
+		ret.Shares = round((csc - psc) / psc * 100)
+		ret.Dilution = math.Max(ret.Shares, 0)
+	}
+
+	ret.PsGrowth = make(perShareGrowths)
+	for _, basis := range shareBases {
+		if g := newPerShareGrowth(pastMeasure, currentMeasure, basis); g != nil {
+			ret.PsGrowth[basis] = g
+		}
+	}
+
+	//DuPont attribution. Ignore error as total assets may not be known
+	ret.RoeNm, ret.RoeAt, ret.RoeEm, _ = dupontAttribution(past, current)
+
 	return ret, nil
 
 }
@@ -164,3 +267,62 @@ func (y *yoy) DividendGrowth() float64 {
 func (y *yoy) BookValueGrowth() float64 {
 	return y.Bv
 }
+
+func (y *yoy) OwnerEarningsGrowth() float64 {
+	return y.Oe
+}
+
+func (y *yoy) TangibleBookValueGrowth() float64 {
+	return y.Tbv
+}
+
+func (y *yoy) ReturnOnTangibleEquityGrowth() float64 {
+	return y.RoTE
+}
+
+func (y *yoy) GoodwillToEquityGrowth() float64 {
+	return y.GwToEq
+}
+
+func (y *yoy) NetMarginContribution() float64 {
+	return y.RoeNm
+}
+
+func (y *yoy) AssetTurnoverContribution() float64 {
+	return y.RoeAt
+}
+
+func (y *yoy) EquityMultiplierContribution() float64 {
+	return y.RoeEm
+}
+
+func (y *yoy) ReturnOnInvestedCapitalGrowth() float64 {
+	return y.RoIC
+}
+
+func (y *yoy) IncrementalReturnOnInvestedCapital() float64 {
+	return y.IncRoIC
+}
+
+func (y *yoy) ReinvestmentRate() float64 {
+	return y.Reinvest
+}
+
+func (y *yoy) NetShareChange() float64 {
+	return y.Shares
+}
+
+func (y *yoy) DilutionRate() float64 {
+	return y.Dilution
+}
+
+func (y *yoy) Past() Measures {
+	return y.past
+}
+
+func (y *yoy) PerShareGrowth(basis ShareBasis) PerShareGrowth {
+	if g, ok := y.PsGrowth[basis]; ok {
+		return g
+	}
+	return nil
+}